@@ -0,0 +1,93 @@
+package woocommerce
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxBatchSize is the number of objects WooCommerce accepts in a batch request,
+// also used as the page size when listing everything
+const maxBatchSize = 100
+
+// BatchItemError is what WooCommerce reports for an item of a batch request it
+// rejected, the other items of the batch are still applied.
+type BatchItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Status int `json:"status"`
+	} `json:"data"`
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// listAllPages calls list with increasing pages until a short page comes back
+func listAllPages[T any](list func(options ListOptions) ([]T, error)) ([]T, error) {
+	var all []T
+	for page := 1; ; page++ {
+		items, err := list(ListOptions{Page: page, PerPage: maxBatchSize})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < maxBatchSize {
+			return all, nil
+		}
+	}
+}
+
+// batchChunks splits creations, updates and deletions into batches of at most
+// maxBatchSize objects, in that order, and calls send with each batch
+func batchChunks[T any](create, update []T, ids []int64, send func(create, update []T, ids []int64) error) error {
+	for len(create)+len(update)+len(ids) > 0 {
+		room := maxBatchSize
+		take := func(n int) int {
+			if n > room {
+				n = room
+			}
+			room -= n
+			return n
+		}
+		c, u, d := take(len(create)), take(len(update)), take(len(ids))
+		if err := send(create[:c], update[:u], ids[:d]); err != nil {
+			return err
+		}
+		create, update, ids = create[c:], update[u:], ids[d:]
+	}
+	return nil
+}
+
+// batchItemsError returns the errors of the items WooCommerce rejected in a batch
+// response, named by the key of the item sent at the same position
+func batchItemsError[T any](kind string, sent []T, received []*T, key func(T) string, itemError func(*T) *BatchItemError) error {
+	var errs []error
+	for i, item := range received {
+		if item == nil || itemError(item) == nil {
+			continue
+		}
+		k := "?"
+		if i < len(sent) {
+			k = key(sent[i])
+		}
+		errs = append(errs, fmt.Errorf("%s %q: %w", kind, k, itemError(item)))
+	}
+	return errors.Join(errs...)
+}
+
+// batchDeletesError returns the errors of the deletions WooCommerce rejected
+func batchDeletesError[T any](kind string, ids []int64, received []*T, itemError func(*T) *BatchItemError) error {
+	var errs []error
+	for i, item := range received {
+		if item != nil && itemError(item) != nil && i < len(ids) {
+			errs = append(errs, fmt.Errorf("deleting %s %d: %w", kind, ids[i], itemError(item)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func categoryError(c *ProductCategory) *BatchItemError   { return c.Error }
+func productError(p *Product) *BatchItemError            { return p.Error }
+func variationError(v *ProductVariation) *BatchItemError { return v.Error }
+func webhookError(w *Webhook) *BatchItemError            { return w.Error }
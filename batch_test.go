@@ -0,0 +1,26 @@
+package woocommerce
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBatchChunks(t *testing.T) {
+	create := make([]int, 150)
+	update := make([]int, 30)
+	ids := make([]int64, 40)
+	var sizes []string
+	err := batchChunks(create, update, ids, func(create, update []int, ids []int64) error {
+		sizes = append(sizes, fmt.Sprintf("%d/%d/%d", len(create), len(update), len(ids)))
+		return nil
+	})
+	if err != nil || fmt.Sprint(sizes) != "[100/0/0 50/30/20 0/0/20]" {
+		t.Errorf("batchChunks() sent %v, %v", sizes, err)
+	}
+
+	calls := 0
+	wantErr := fmt.Errorf("boom")
+	if err := batchChunks(create, nil, nil, func([]int, []int, []int64) error { calls++; return wantErr }); err != wantErr || calls != 1 {
+		t.Errorf("batchChunks() = %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,227 @@
+package woocommerce
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a GET response kept by a CacheStore
+type CachedResponse struct {
+	Header   http.Header
+	Body     []byte
+	StoredAt time.Time
+}
+
+// CacheStore keeps cached responses, implementations must be safe for concurrent use
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse)
+	// DeletePrefix removes the responses whose key starts with prefix.
+	DeletePrefix(prefix string)
+}
+
+// DefaultCacheTTLs caches the reference data of a store, which rarely changes
+var DefaultCacheTTLs = map[string]time.Duration{
+	"products.categories":       10 * time.Minute,
+	"products.tags":             10 * time.Minute,
+	"products.attributes":       10 * time.Minute,
+	"products.attributes.terms": 10 * time.Minute,
+	"products.shipping_classes": 10 * time.Minute,
+	"payment_gateways":          10 * time.Minute,
+}
+
+// CacheOptions configures the caching of GET responses
+type CacheOptions struct {
+	// Store keeps the responses, a MemoryCacheStore when nil. Share a store only
+	// between clients using the same credentials.
+	Store CacheStore
+	// TTLs is how long responses are fresh, by resource as named by Call.Resource,
+	// e.g. "products.categories". DefaultCacheTTLs when nil.
+	TTLs map[string]time.Duration
+	// DefaultTTL applies to the resources missing from TTLs, 0 to not cache them.
+	DefaultTTL time.Duration
+}
+
+// WithCache caches GET responses, see CacheMiddleware
+func WithCache(options CacheOptions) Option {
+	return WithMiddleware(CacheMiddleware(options))
+}
+
+// CacheMiddleware serves GET requests from a cache while their response is fresh.
+// Once stale, responses carrying an ETag or Last-Modified header are revalidated
+// with a conditional request. Writes through the client invalidate the cached
+// responses of the collection written to, e.g. updating "products/categories/9"
+// drops every cached "products/categories" response.
+func CacheMiddleware(options CacheOptions) Middleware {
+	store := options.Store
+	if store == nil {
+		store = NewMemoryCacheStore()
+	}
+	ttls := options.TTLs
+	if ttls == nil {
+		ttls = DefaultCacheTTLs
+	}
+	ttl := func(resource string) time.Duration {
+		if ttl, ok := ttls[resource]; ok {
+			return ttl
+		}
+		return options.DefaultTTL
+	}
+
+	return func(next CallHandler) CallHandler {
+		return func(call *Call) (*http.Response, error) {
+			host := call.Request.URL.Host
+			if call.Method != http.MethodGet {
+				resp, err := next(call)
+				if err == nil && resp.StatusCode < http.StatusBadRequest {
+					collection := cacheCollection(call.Path)
+					store.DeletePrefix(host + "/" + collection + "/")
+					store.DeletePrefix(host + "/" + collection + "?")
+				}
+				return resp, err
+			}
+
+			maxAge := ttl(call.Resource())
+			if maxAge <= 0 {
+				return next(call)
+			}
+			key := host + "/" + call.Path + "?" + call.Request.URL.RawQuery
+			cached, ok := store.Get(key)
+			if ok && time.Since(cached.StoredAt) < maxAge {
+				return cached.response(call.Request), nil
+			}
+			if ok {
+				if etag := cached.Header.Get("ETag"); etag != "" {
+					call.Request.Header.Set("If-None-Match", etag)
+				}
+				if modified := cached.Header.Get("Last-Modified"); modified != "" {
+					call.Request.Header.Set("If-Modified-Since", modified)
+				}
+			}
+
+			resp, err := next(call)
+			if err != nil {
+				return resp, err
+			}
+			switch {
+			case resp.StatusCode == http.StatusNotModified && ok:
+				resp.Body.Close()
+				store.Set(key, &CachedResponse{Header: cached.Header, Body: cached.Body, StoredAt: time.Now()})
+				return cached.response(call.Request), nil
+			case resp.StatusCode == http.StatusOK:
+				body, err := ReadResponseBody(resp)
+				if err != nil {
+					return resp, err
+				}
+				store.Set(key, &CachedResponse{Header: resp.Header.Clone(), Body: body, StoredAt: time.Now()})
+			}
+			return resp, nil
+		}
+	}
+}
+
+func (c *CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     c.Header.Clone(),
+		Body:       io.NopCloser(bytes.NewReader(c.Body)),
+		Request:    req,
+	}
+}
+
+// cacheCollection is the top collection of path, e.g. "products" for
+// "products/12/variations/3" and "products/categories" for "products/categories/9"
+func cacheCollection(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	end := 0
+	for end < len(segments) && segments[end] != "batch" && !isNumeric(segments[end]) {
+		end++
+	}
+	return strings.Join(segments[:end], "/")
+}
+
+// defaultCacheMaxEntries is the MaxEntries of a new MemoryCacheStore
+const defaultCacheMaxEntries = 1000
+
+// MemoryCacheStore is an in-memory CacheStore holding up to MaxEntries responses
+type MemoryCacheStore struct {
+	// MaxEntries caps the number of responses kept, the least recently used are
+	// evicted first. 1000 by default, 0 for no limit.
+	MaxEntries int
+
+	mu        sync.Mutex
+	responses map[string]*list.Element
+	// recent orders the entries, most recently used first
+	recent *list.List
+}
+
+type memoryCacheEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewMemoryCacheStore returns an empty MemoryCacheStore
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{
+		MaxEntries: defaultCacheMaxEntries,
+		responses:  make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+}
+
+// Get implements CacheStore
+func (s *MemoryCacheStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.responses[key]
+	if !ok {
+		return nil, false
+	}
+	s.recent.MoveToFront(element)
+	return element.Value.(*memoryCacheEntry).response, true
+}
+
+// Set implements CacheStore
+func (s *MemoryCacheStore) Set(key string, response *CachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.responses[key]; ok {
+		element.Value.(*memoryCacheEntry).response = response
+		s.recent.MoveToFront(element)
+		return
+	}
+	s.responses[key] = s.recent.PushFront(&memoryCacheEntry{key: key, response: response})
+	for s.MaxEntries > 0 && s.recent.Len() > s.MaxEntries {
+		oldest := s.recent.Back()
+		s.recent.Remove(oldest)
+		delete(s.responses, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// DeletePrefix implements CacheStore
+func (s *MemoryCacheStore) DeletePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, element := range s.responses {
+		if strings.HasPrefix(key, prefix) {
+			s.recent.Remove(element)
+			delete(s.responses, key)
+		}
+	}
+}
+
+// Len returns the number of responses stored
+func (s *MemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.responses)
+}
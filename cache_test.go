@@ -0,0 +1,95 @@
+package woocommerce
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCacheMiddleware(t *testing.T) {
+	requests := map[string]int{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products/categories", func(w http.ResponseWriter, r *http.Request) {
+		requests["categories"]++
+		w.Header().Set("X-WP-Total", "1")
+		w.Write([]byte(`[{"id":9,"name":"Mugs"}]`))
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/categories/9", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Method+" category"]++
+		w.Write([]byte(`{"id":9,"name":"Cups"}`))
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/tags", func(w http.ResponseWriter, r *http.Request) {
+		requests["tags"]++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`[{"id":3,"name":"sale"}]`))
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products", func(w http.ResponseWriter, r *http.Request) {
+		requests["products"]++
+		w.Write([]byte(`[]`))
+	})
+	store := NewMemoryCacheStore()
+	c := newTestClient(t, mux, WithCache(CacheOptions{
+		Store: store,
+		TTLs:  map[string]time.Duration{"products.categories": time.Minute, "products.tags": time.Nanosecond},
+	}))
+
+	for i := 0; i < 3; i++ {
+		categories, err := c.ProductCategory.List(nil)
+		if err != nil || len(categories) != 1 || categories[0].Name != "Mugs" {
+			t.Fatalf("List() = %+v, %v", categories, err)
+		}
+	}
+	if requests["categories"] != 1 {
+		t.Errorf("categories fetched %d times", requests["categories"])
+	}
+
+	if _, err := c.ProductCategory.Update(&ProductCategory{ID: 9, Name: "Cups"}); err != nil {
+		t.Fatalf("Update() err = %v", err)
+	}
+	c.ProductCategory.List(nil)
+	if requests["categories"] != 2 {
+		t.Errorf("categories fetched %d times after an update", requests["categories"])
+	}
+
+	for i := 0; i < 2; i++ {
+		tags, err := c.ProductTag.List(nil)
+		if err != nil || len(tags) != 1 || tags[0].Name != "sale" {
+			t.Fatalf("tags List() = %+v, %v", tags, err)
+		}
+	}
+	if requests["tags"] != 2 {
+		t.Errorf("tags fetched %d times, want a fetch and a revalidation", requests["tags"])
+	}
+
+	c.Product.List(nil)
+	c.Product.List(nil)
+	if requests["products"] != 2 {
+		t.Errorf("uncached resource fetched %d times", requests["products"])
+	}
+}
+
+func TestMemoryCacheStore_Evicts(t *testing.T) {
+	store := NewMemoryCacheStore()
+	store.MaxEntries = 2
+	store.Set("a", &CachedResponse{Body: []byte("a")})
+	store.Set("b", &CachedResponse{Body: []byte("b")})
+	store.Get("a")
+	store.Set("c", &CachedResponse{Body: []byte("c")})
+
+	if _, ok := store.Get("b"); ok {
+		t.Error("least recently used response was kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if response, ok := store.Get(key); !ok || string(response.Body) != key {
+			t.Errorf("Get(%q) = %v, %v", key, response, ok)
+		}
+	}
+	store.DeletePrefix("a")
+	if store.Len() != 1 {
+		t.Errorf("Len() = %d after DeletePrefix", store.Len())
+	}
+}
@@ -0,0 +1,694 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Catalog is the desired state of a store's catalog. Categories, tags, attributes
+// and shipping classes are identified by slug, products by SKU. Only the non-zero
+// fields of each item are managed, the others are left as they are in the store.
+// A nil list leaves its kind unmanaged, while an empty list manages it with no items.
+type Catalog struct {
+	ShippingClasses []ProductShippingClass `json:"shipping_classes"`
+	Tags            []ProductTag           `json:"tags"`
+	Attributes      []ProductAttributeData `json:"attributes"`
+	Categories      []CatalogCategory      `json:"categories"`
+	// Products reference their categories and tags by slug, ProductCategoryRef.Slug and ProductTagRef.Slug.
+	Products []Product `json:"products"`
+}
+
+// CatalogCategory is a category of a Catalog, whose parent is referenced by slug
+type CatalogCategory struct {
+	ProductCategory
+	ParentSlug string `json:"parent_slug,omitempty"`
+}
+
+// LoadCatalog decodes a JSON catalog, using the field names of the REST API.
+// The yamlwoo package loads YAML catalogs.
+func LoadCatalog(r io.Reader) (*Catalog, error) {
+	catalog := &Catalog{}
+	if err := json.NewDecoder(r).Decode(catalog); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// CatalogFromDocument converts an already decoded document, e.g. parsed from
+// YAML, to a Catalog using the field names of the REST API
+func CatalogFromDocument(document interface{}) (*Catalog, error) {
+	catalog := &Catalog{}
+	if document == nil {
+		return catalog, nil
+	}
+	// round trip through JSON so that the API field names and types apply
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, catalog); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// Catalog item kinds, in the order they are applied
+const (
+	CatalogShippingClass = "shipping_class"
+	CatalogTag           = "tag"
+	CatalogAttribute     = "attribute"
+	CatalogCategoryKind  = "category"
+	CatalogProduct       = "product"
+)
+
+// CatalogAction is what a plan does to a catalog item
+type CatalogAction string
+
+const (
+	CatalogCreate CatalogAction = "create"
+	CatalogUpdate CatalogAction = "update"
+	CatalogDelete CatalogAction = "delete"
+)
+
+// FieldDiff is a field whose current value differs from the desired one
+type FieldDiff struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// CatalogChange is one planned change to a catalog item
+type CatalogChange struct {
+	Kind   string
+	Key    string
+	Action CatalogAction
+	// ID is the ID of the item in the store, zero for creations.
+	ID     int64
+	Fields []FieldDiff
+
+	item interface{}
+}
+
+// CatalogPlan lists the changes bringing the store to a Catalog
+type CatalogPlan struct {
+	Changes []CatalogChange
+
+	// ids maps kind and key to the IDs of the items already in the store
+	ids map[string]map[string]int64
+}
+
+// Empty reports whether the store already matches the catalog
+func (p *CatalogPlan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String renders the plan for humans, one change per line followed by its field diffs
+func (p *CatalogPlan) String() string {
+	var b strings.Builder
+	counts := map[CatalogAction]int{}
+	symbols := map[CatalogAction]string{CatalogCreate: "+", CatalogUpdate: "~", CatalogDelete: "-"}
+	for _, change := range p.Changes {
+		counts[change.Action]++
+		fmt.Fprintf(&b, "%s %s %q\n", symbols[change.Action], change.Kind, change.Key)
+		for _, field := range change.Fields {
+			if change.Action == CatalogCreate {
+				fmt.Fprintf(&b, "    %s: %s\n", field.Field, formatPlanValue(field.New))
+			} else {
+				fmt.Fprintf(&b, "    %s: %s => %s\n", field.Field, formatPlanValue(field.Old), formatPlanValue(field.New))
+			}
+		}
+	}
+	fmt.Fprintf(&b, "Plan: %d to create, %d to update, %d to delete.\n", counts[CatalogCreate], counts[CatalogUpdate], counts[CatalogDelete])
+	return b.String()
+}
+
+func formatPlanValue(value interface{}) string {
+	if value == nil {
+		return "(none)"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// CatalogReconciler plans and applies a Catalog against a store
+type CatalogReconciler struct {
+	client *Client
+	// DryRun makes Apply return the plan without changing the store.
+	DryRun bool
+	// Prune deletes the items of managed kinds that are not in the catalog.
+	Prune bool
+}
+
+// NewCatalogReconciler returns a CatalogReconciler working through the client's services
+func NewCatalogReconciler(c *Client) *CatalogReconciler {
+	return &CatalogReconciler{client: c}
+}
+
+// Plan fetches the current catalog and computes the changes needed to reach desired
+func (r *CatalogReconciler) Plan(desired *Catalog) (*CatalogPlan, error) {
+	plan := &CatalogPlan{ids: map[string]map[string]int64{}}
+	c := r.client
+
+	if desired.ShippingClasses != nil {
+		current, err := listAllPages(func(o ListOptions) ([]ProductShippingClass, error) {
+			return c.ProductShippingClass.List(ProductShippingClassListOption{ListOptions: o})
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = planKind(plan, r.Prune, CatalogShippingClass, desired.ShippingClasses, current,
+			func(s ProductShippingClass) string { return s.Slug },
+			func(s ProductShippingClass) int64 { return s.ID })
+		if err != nil {
+			return nil, err
+		}
+	}
+	if desired.Tags != nil {
+		current, err := listAllPages(func(o ListOptions) ([]ProductTag, error) {
+			return c.ProductTag.List(ProductTagListOption{ListOptions: o})
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = planKind(plan, r.Prune, CatalogTag, desired.Tags, current,
+			func(t ProductTag) string { return t.Slug },
+			func(t ProductTag) int64 { return t.ID })
+		if err != nil {
+			return nil, err
+		}
+	}
+	if desired.Attributes != nil {
+		// the attributes endpoint is not paginated
+		current, err := c.ProductAttribute.List(nil)
+		if err != nil {
+			return nil, err
+		}
+		err = planKind(plan, r.Prune, CatalogAttribute, trimAttributeSlugs(desired.Attributes), trimAttributeSlugs(current),
+			func(a ProductAttributeData) string { return a.Slug },
+			func(a ProductAttributeData) int64 { return a.ID })
+		if err != nil {
+			return nil, err
+		}
+	}
+	if desired.Categories != nil || desired.Products != nil {
+		current, err := listAllPages(func(o ListOptions) ([]ProductCategory, error) {
+			return c.ProductCategory.List(ProductCategoryListOption{ListOptions: o})
+		})
+		if err != nil {
+			return nil, err
+		}
+		slugs := make(map[int64]string, len(current))
+		for _, category := range current {
+			slugs[category.ID] = category.Slug
+		}
+		wrapped := make([]CatalogCategory, 0, len(current))
+		for _, category := range current {
+			wrapped = append(wrapped, CatalogCategory{ProductCategory: category, ParentSlug: slugs[category.ParentID]})
+		}
+		if err := checkCategoryParents(desired.Categories, wrapped); err != nil {
+			return nil, err
+		}
+		categories := desired.Categories
+		if categories == nil {
+			// products still need the IDs of existing categories
+			categories = []CatalogCategory{}
+		}
+		err = planKind(plan, r.Prune && desired.Categories != nil, CatalogCategoryKind, categories, wrapped,
+			func(c CatalogCategory) string { return c.Slug },
+			func(c CatalogCategory) int64 { return c.ID })
+		if err != nil {
+			return nil, err
+		}
+	}
+	if desired.Tags == nil && desired.Products != nil {
+		current, err := listAllPages(func(o ListOptions) ([]ProductTag, error) {
+			return c.ProductTag.List(ProductTagListOption{ListOptions: o})
+		})
+		if err != nil {
+			return nil, err
+		}
+		plan.ids[CatalogTag] = map[string]int64{}
+		for _, tag := range current {
+			plan.ids[CatalogTag][tag.Slug] = tag.ID
+		}
+	}
+	if desired.Products != nil {
+		current, err := listAllPages(func(o ListOptions) ([]Product, error) {
+			return c.Product.List(ProductListOption{ListOptions: o})
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = planKind(plan, r.Prune, CatalogProduct, desired.Products, current,
+			func(p Product) string { return p.SKU },
+			func(p Product) int64 { return p.ID })
+		if err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// trimAttributeSlugs drops the "pa_" prefix WooCommerce gives attribute slugs,
+// so that a declared slug matches and diffs equal with or without it
+func trimAttributeSlugs(attributes []ProductAttributeData) []ProductAttributeData {
+	trimmed := make([]ProductAttributeData, len(attributes))
+	for i, attribute := range attributes {
+		attribute.Slug = strings.TrimPrefix(attribute.Slug, "pa_")
+		trimmed[i] = attribute
+	}
+	return trimmed
+}
+
+// planKind appends the changes of one kind of item to the plan
+func planKind[T any](plan *CatalogPlan, prune bool, kind string, desired, current []T, key func(T) string, id func(T) int64) error {
+	currentByKey := make(map[string]T, len(current))
+	plan.ids[kind] = make(map[string]int64, len(current))
+	for _, item := range current {
+		if k := key(item); k != "" {
+			currentByKey[k] = item
+			plan.ids[kind][k] = id(item)
+		}
+	}
+
+	wanted := make(map[string]bool, len(desired))
+	for _, item := range desired {
+		k := key(item)
+		if k == "" {
+			return fmt.Errorf("catalog %s without %s", kind, keyName(kind))
+		}
+		if wanted[k] {
+			return fmt.Errorf("catalog %s %q is declared twice", kind, k)
+		}
+		wanted[k] = true
+
+		existing, exists := currentByKey[k]
+		fields, err := diffFields(item, existing, exists)
+		if err != nil {
+			return err
+		}
+		switch {
+		case !exists:
+			plan.Changes = append(plan.Changes, CatalogChange{Kind: kind, Key: k, Action: CatalogCreate, Fields: fields, item: item})
+		case len(fields) > 0:
+			plan.Changes = append(plan.Changes, CatalogChange{Kind: kind, Key: k, Action: CatalogUpdate, ID: id(existing), Fields: fields, item: item})
+		}
+	}
+
+	if prune {
+		var deletes []CatalogChange
+		for k, item := range currentByKey {
+			if !wanted[k] {
+				deletes = append(deletes, CatalogChange{Kind: kind, Key: k, Action: CatalogDelete, ID: id(item)})
+			}
+		}
+		sort.Slice(deletes, func(i, j int) bool { return deletes[i].Key < deletes[j].Key })
+		plan.Changes = append(plan.Changes, deletes...)
+	}
+	return nil
+}
+
+func keyName(kind string) string {
+	if kind == CatalogProduct {
+		return "sku"
+	}
+	return "slug"
+}
+
+// catalogIgnoredFields are read-only or resolved separately
+var catalogIgnoredFields = map[string]bool{"id": true, "parent": true, "_links": true, "count": true}
+
+// diffFields compares the declared (non-zero) fields of desired with current.
+// Nested values match when the declared part of them matches, so that a category
+// referenced by slug matches the full category summary returned by the API.
+func diffFields(desired, current interface{}, exists bool) ([]FieldDiff, error) {
+	want, err := jsonMap(desired)
+	if err != nil {
+		return nil, err
+	}
+	have := map[string]interface{}{}
+	if exists {
+		if have, err = jsonMap(current); err != nil {
+			return nil, err
+		}
+	}
+	var fields []FieldDiff
+	for _, name := range sortedFieldNames(want) {
+		if catalogIgnoredFields[name] {
+			continue
+		}
+		if !exists || !jsonSubset(want[name], have[name]) {
+			fields = append(fields, FieldDiff{Field: name, Old: have[name], New: want[name]})
+		}
+	}
+	return fields, nil
+}
+
+func jsonMap(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	return m, json.Unmarshal(data, &m)
+}
+
+func sortedFieldNames(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jsonSubset reports whether every value declared in want is present in have
+func jsonSubset(want, have interface{}) bool {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		h, ok := have.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range w {
+			if !jsonSubset(v, h[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		h, ok := have.([]interface{})
+		if !ok || len(h) != len(w) {
+			return false
+		}
+		for i := range w {
+			if !jsonSubset(w[i], h[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(want, have)
+}
+
+// checkCategoryParents makes sure every parent slug names a category that will exist
+func checkCategoryParents(desired, current []CatalogCategory) error {
+	known := map[string]bool{}
+	for _, category := range current {
+		known[category.Slug] = true
+	}
+	for _, category := range desired {
+		known[category.Slug] = true
+	}
+	for _, category := range desired {
+		if category.ParentSlug != "" && !known[category.ParentSlug] {
+			return fmt.Errorf("category %q has unknown parent %q", category.Slug, category.ParentSlug)
+		}
+	}
+	return nil
+}
+
+// Apply plans the catalog and, unless DryRun is set, carries the plan out with
+// batch calls: shipping classes, tags and attributes first, then categories
+// parents first, then products, and finally the deletions in reverse order.
+// The plan is returned in both cases.
+func (r *CatalogReconciler) Apply(desired *Catalog) (*CatalogPlan, error) {
+	plan, err := r.Plan(desired)
+	if err != nil || r.DryRun || plan.Empty() {
+		return plan, err
+	}
+	return plan, r.apply(plan)
+}
+
+func (r *CatalogReconciler) apply(plan *CatalogPlan) error {
+	c := r.client
+	changes := map[string]map[CatalogAction][]CatalogChange{}
+	for _, change := range plan.Changes {
+		if changes[change.Kind] == nil {
+			changes[change.Kind] = map[CatalogAction][]CatalogChange{}
+		}
+		changes[change.Kind][change.Action] = append(changes[change.Kind][change.Action], change)
+	}
+	record := func(kind, key string, id int64) {
+		if plan.ids[kind] == nil {
+			plan.ids[kind] = map[string]int64{}
+		}
+		plan.ids[kind][key] = id
+	}
+
+	// shipping classes
+	err := applyChanges(changes[CatalogShippingClass], func(change CatalogChange) ProductShippingClass {
+		item := change.item.(ProductShippingClass)
+		item.ID = change.ID
+		return item
+	}, func(create, update []ProductShippingClass) error {
+		resource, err := c.ProductShippingClass.Batch(ProductShippingClassBatchOption{Create: create, Update: update})
+		if err != nil {
+			return err
+		}
+		key := func(s ProductShippingClass) string { return s.Slug }
+		itemError := func(s *ProductShippingClass) *BatchItemError { return s.Error }
+		if err := batchItemsError(CatalogShippingClass, create, resource.Create, key, itemError); err != nil {
+			return err
+		}
+		if err := batchItemsError(CatalogShippingClass, update, resource.Update, key, itemError); err != nil {
+			return err
+		}
+		for _, item := range resource.Create {
+			if item != nil {
+				record(CatalogShippingClass, item.Slug, item.ID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// tags
+	err = applyChanges(changes[CatalogTag], func(change CatalogChange) ProductTag {
+		item := change.item.(ProductTag)
+		item.ID = change.ID
+		return item
+	}, func(create, update []ProductTag) error {
+		resource, err := c.ProductTag.Batch(ProductTagBatchOption{Create: create, Update: update})
+		if err != nil {
+			return err
+		}
+		key := func(t ProductTag) string { return t.Slug }
+		itemError := func(t *ProductTag) *BatchItemError { return t.Error }
+		if err := batchItemsError(CatalogTag, create, resource.Create, key, itemError); err != nil {
+			return err
+		}
+		if err := batchItemsError(CatalogTag, update, resource.Update, key, itemError); err != nil {
+			return err
+		}
+		for _, item := range resource.Create {
+			if item != nil {
+				record(CatalogTag, item.Slug, item.ID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// attributes
+	err = applyChanges(changes[CatalogAttribute], func(change CatalogChange) ProductAttributeData {
+		item := change.item.(ProductAttributeData)
+		item.ID = change.ID
+		return item
+	}, func(create, update []ProductAttributeData) error {
+		resource, err := c.ProductAttribute.Batch(ProductAttributeBatchOption{Create: create, Update: update})
+		if err != nil {
+			return err
+		}
+		key := func(a ProductAttributeData) string { return a.Slug }
+		itemError := func(a *ProductAttributeData) *BatchItemError { return a.Error }
+		if err := batchItemsError(CatalogAttribute, create, resource.Create, key, itemError); err != nil {
+			return err
+		}
+		return batchItemsError(CatalogAttribute, update, resource.Update, key, itemError)
+	})
+	if err != nil {
+		return err
+	}
+
+	// categories, one batch of creations per level so that parents get an ID first
+	pending := changes[CatalogCategoryKind][CatalogCreate]
+	for len(pending) > 0 {
+		var ready, waiting []CatalogChange
+		for _, change := range pending {
+			parent := change.item.(CatalogCategory).ParentSlug
+			if _, ok := plan.ids[CatalogCategoryKind][parent]; parent == "" || ok {
+				ready = append(ready, change)
+			} else {
+				waiting = append(waiting, change)
+			}
+		}
+		if len(ready) == 0 {
+			return fmt.Errorf("category %q is part of a parent cycle", waiting[0].Key)
+		}
+		err := applyChanges(map[CatalogAction][]CatalogChange{CatalogCreate: ready}, func(change CatalogChange) ProductCategory {
+			return resolveCategoryParent(change, plan)
+		}, func(create, _ []ProductCategory) error {
+			resource, err := c.ProductCategory.Batch(ProductCategoryBatchOption{Create: create})
+			if err != nil {
+				return err
+			}
+			if err := batchItemsError(CatalogCategoryKind, create, resource.Create, categorySlug, categoryError); err != nil {
+				return err
+			}
+			for _, item := range resource.Create {
+				if item != nil {
+					record(CatalogCategoryKind, item.Slug, item.ID)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		pending = waiting
+	}
+	err = applyChanges(map[CatalogAction][]CatalogChange{CatalogUpdate: changes[CatalogCategoryKind][CatalogUpdate]}, func(change CatalogChange) ProductCategory {
+		return resolveCategoryParent(change, plan)
+	}, func(_, update []ProductCategory) error {
+		resource, err := c.ProductCategory.Batch(ProductCategoryBatchOption{Update: update})
+		if err != nil {
+			return err
+		}
+		return batchItemsError(CatalogCategoryKind, update, resource.Update, categorySlug, categoryError)
+	})
+	if err != nil {
+		return err
+	}
+
+	// products
+	var resolveErr error
+	err = applyChanges(changes[CatalogProduct], func(change CatalogChange) Product {
+		item := change.item.(Product)
+		item.ID = change.ID
+		item.Categories = append([]ProductCategoryRef(nil), item.Categories...)
+		for i, ref := range item.Categories {
+			if id, ok := plan.ids[CatalogCategoryKind][ref.Slug]; ok && ref.ID == 0 {
+				item.Categories[i] = ProductCategoryRef{ID: id}
+			} else if ref.ID == 0 && resolveErr == nil {
+				resolveErr = fmt.Errorf("product %q references unknown category %q", change.Key, ref.Slug)
+			}
+		}
+		item.Tags = append([]ProductTagRef(nil), item.Tags...)
+		for i, ref := range item.Tags {
+			if id, ok := plan.ids[CatalogTag][ref.Slug]; ok && ref.ID == 0 {
+				item.Tags[i] = ProductTagRef{ID: id}
+			} else if ref.ID == 0 && resolveErr == nil {
+				resolveErr = fmt.Errorf("product %q references unknown tag %q", change.Key, ref.Slug)
+			}
+		}
+		return item
+	}, func(create, update []Product) error {
+		if resolveErr != nil {
+			return resolveErr
+		}
+		resource, err := c.Product.Batch(ProductBatchOption{Create: create, Update: update})
+		if err != nil {
+			return err
+		}
+		if err := batchItemsError(CatalogProduct, create, resource.Create, productSKU, productError); err != nil {
+			return err
+		}
+		return batchItemsError(CatalogProduct, update, resource.Update, productSKU, productError)
+	})
+	if err != nil {
+		return err
+	}
+
+	// deletions, dependants first
+	deletes := func(kind string, send func(ids []int64) error) error {
+		ids := make([]int64, 0, len(changes[kind][CatalogDelete]))
+		for _, change := range changes[kind][CatalogDelete] {
+			ids = append(ids, change.ID)
+		}
+		return batchChunks(nil, nil, ids, func(_, _ []int64, ids []int64) error {
+			return send(ids)
+		})
+	}
+	if err := deletes(CatalogProduct, func(ids []int64) error {
+		resource, err := c.Product.Batch(ProductBatchOption{Delete: ids})
+		if err != nil {
+			return err
+		}
+		return batchDeletesError(CatalogProduct, ids, resource.Delete, productError)
+	}); err != nil {
+		return err
+	}
+	if err := deletes(CatalogCategoryKind, func(ids []int64) error {
+		resource, err := c.ProductCategory.Batch(ProductCategoryBatchOption{Delete: ids})
+		if err != nil {
+			return err
+		}
+		return batchDeletesError(CatalogCategoryKind, ids, resource.Delete, categoryError)
+	}); err != nil {
+		return err
+	}
+	if err := deletes(CatalogAttribute, func(ids []int64) error {
+		resource, err := c.ProductAttribute.Batch(ProductAttributeBatchOption{Delete: ids})
+		if err != nil {
+			return err
+		}
+		return batchDeletesError(CatalogAttribute, ids, resource.Delete, func(a *ProductAttributeData) *BatchItemError { return a.Error })
+	}); err != nil {
+		return err
+	}
+	if err := deletes(CatalogTag, func(ids []int64) error {
+		resource, err := c.ProductTag.Batch(ProductTagBatchOption{Delete: ids})
+		if err != nil {
+			return err
+		}
+		return batchDeletesError(CatalogTag, ids, resource.Delete, func(t *ProductTag) *BatchItemError { return t.Error })
+	}); err != nil {
+		return err
+	}
+	return deletes(CatalogShippingClass, func(ids []int64) error {
+		resource, err := c.ProductShippingClass.Batch(ProductShippingClassBatchOption{Delete: ids})
+		if err != nil {
+			return err
+		}
+		return batchDeletesError(CatalogShippingClass, ids, resource.Delete, func(s *ProductShippingClass) *BatchItemError { return s.Error })
+	})
+}
+
+func categorySlug(c ProductCategory) string { return c.Slug }
+func productSKU(p Product) string           { return p.SKU }
+
+func resolveCategoryParent(change CatalogChange, plan *CatalogPlan) ProductCategory {
+	item := change.item.(CatalogCategory)
+	category := item.ProductCategory
+	category.ID = change.ID
+	if item.ParentSlug != "" {
+		category.ParentID = plan.ids[CatalogCategoryKind][item.ParentSlug]
+	}
+	return category
+}
+
+// applyChanges sends the creations and updates of one kind in batches WooCommerce accepts
+func applyChanges[T any](changes map[CatalogAction][]CatalogChange, build func(CatalogChange) T, send func(create, update []T) error) error {
+	var create, update []T
+	for _, change := range changes[CatalogCreate] {
+		create = append(create, build(change))
+	}
+	for _, change := range changes[CatalogUpdate] {
+		update = append(update, build(change))
+	}
+	return batchChunks(create, update, nil, func(create, update []T, _ []int64) error {
+		return send(create, update)
+	})
+}
@@ -0,0 +1,171 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const testCatalogJSON = `{
+  "tags": [{"name": "Organic", "slug": "organic"}],
+  "categories": [
+    {"name": "Apparel", "slug": "apparel"},
+    {"name": "Men", "slug": "men", "parent_slug": "apparel"}
+  ],
+  "products": [
+    {"sku": "TSHIRT", "name": "T-Shirt", "regular_price": "12.00", "categories": [{"slug": "men"}], "tags": [{"slug": "organic"}]},
+    {"sku": "MUG", "name": "Mug", "regular_price": "8.00", "categories": [{"slug": "apparel"}]}
+  ]
+}`
+
+// catalogTestStore serves a store holding the Apparel category, the MUG and OLD
+// products, and records the batch payloads it receives.
+func catalogTestStore(t *testing.T) (*Client, map[string][]json.RawMessage) {
+	batches := map[string][]json.RawMessage{}
+	nextID := int64(100)
+	mux := http.NewServeMux()
+	handle := func(path string, current interface{}) {
+		mux.HandleFunc("/wp-json/wc/v3/"+path, func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(current)
+		})
+		mux.HandleFunc("/wp-json/wc/v3/"+path+"/batch", func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Create []map[string]interface{} `json:"create"`
+			}
+			var raw json.RawMessage
+			json.NewDecoder(r.Body).Decode(&raw)
+			batches[path] = append(batches[path], raw)
+			json.Unmarshal(raw, &body)
+			for _, item := range body.Create {
+				nextID++
+				item["id"] = nextID
+			}
+			json.NewEncoder(w).Encode(body)
+		})
+	}
+	handle("products/tags", []ProductTag{})
+	handle("products/categories", []ProductCategory{{ID: 1, Name: "Apparel", Slug: "apparel"}, {ID: 2, Name: "Old", Slug: "old"}})
+	handle("products", []Product{
+		{ID: 10, SKU: "MUG", Name: "Mug", RegularPrice: "8.00", Categories: []ProductCategoryRef{{ID: 1, Name: "Apparel", Slug: "apparel"}}},
+		{ID: 11, SKU: "OLD", Name: "Old"},
+	})
+	return newTestClient(t, mux), batches
+}
+
+func TestCatalogReconciler_Plan(t *testing.T) {
+	catalog, err := LoadCatalog(strings.NewReader(testCatalogJSON))
+	if err != nil {
+		t.Fatalf("LoadCatalog() err = %v", err)
+	}
+	c, _ := catalogTestStore(t)
+	reconciler := NewCatalogReconciler(c)
+	reconciler.Prune = true
+
+	plan, err := reconciler.Plan(catalog)
+	if err != nil {
+		t.Fatalf("Plan() err = %v", err)
+	}
+	var got []string
+	for _, change := range plan.Changes {
+		got = append(got, string(change.Action)+" "+change.Kind+" "+change.Key)
+	}
+	want := []string{"create tag organic", "create category men", "delete category old", "create product TSHIRT", "delete product OLD"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Plan() = %v, want %v", got, want)
+	}
+	if !strings.Contains(plan.String(), "Plan: 3 to create, 0 to update, 2 to delete.") {
+		t.Errorf("String() = %s", plan.String())
+	}
+
+	catalog.Products[1].RegularPrice = "9.00"
+	reconciler.Prune = false
+	plan, err = reconciler.Plan(catalog)
+	if err != nil {
+		t.Fatalf("Plan() err = %v", err)
+	}
+	last := plan.Changes[len(plan.Changes)-1]
+	if last.Action != CatalogUpdate || last.ID != 10 || len(last.Fields) != 1 || last.Fields[0].Field != "regular_price" || last.Fields[0].Old != "8.00" {
+		t.Errorf("update change = %+v", last)
+	}
+}
+
+func TestCatalogReconciler_Apply(t *testing.T) {
+	catalog, err := LoadCatalog(strings.NewReader(testCatalogJSON))
+	if err != nil {
+		t.Fatalf("LoadCatalog() err = %v", err)
+	}
+	c, batches := catalogTestStore(t)
+	reconciler := NewCatalogReconciler(c)
+
+	reconciler.DryRun = true
+	if _, err := reconciler.Apply(catalog); err != nil || len(batches) != 0 {
+		t.Fatalf("dry run Apply() err = %v, batches = %v", err, batches)
+	}
+
+	reconciler.DryRun = false
+	if _, err := reconciler.Apply(catalog); err != nil {
+		t.Fatalf("Apply() err = %v", err)
+	}
+	var categories ProductCategoryBatchOption
+	json.Unmarshal(batches["products/categories"][0], &categories)
+	if len(categories.Create) != 1 || categories.Create[0].ParentID != 1 {
+		t.Errorf("category batch = %+v", categories)
+	}
+	var products ProductBatchOption
+	json.Unmarshal(batches["products"][0], &products)
+	if len(products.Create) != 1 || products.Create[0].Categories[0].ID == 0 || products.Create[0].Tags[0].ID == 0 || len(products.Delete) != 0 {
+		t.Errorf("product batch = %+v", products)
+	}
+}
+
+func TestCatalogReconciler_AttributesConverge(t *testing.T) {
+	attributes := []ProductAttributeData{{ID: 1, Name: "Size", Slug: "pa_size"}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products/attributes", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(attributes)
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/attributes/batch", func(w http.ResponseWriter, r *http.Request) {
+		var batch ProductAttributeBatchOption
+		json.NewDecoder(r.Body).Decode(&batch)
+		for i := range batch.Create {
+			// WooCommerce prefixes the slugs of global attributes
+			batch.Create[i].ID = int64(len(attributes) + 1)
+			batch.Create[i].Slug = "pa_" + batch.Create[i].Slug
+			attributes = append(attributes, batch.Create[i])
+		}
+		json.NewEncoder(w).Encode(batch)
+	})
+	reconciler := NewCatalogReconciler(newTestClient(t, mux))
+	catalog := &Catalog{Attributes: []ProductAttributeData{{Name: "Size", Slug: "size"}, {Name: "Color", Slug: "color"}}}
+
+	plan, err := reconciler.Apply(catalog)
+	if err != nil || len(plan.Changes) != 1 || plan.Changes[0].Action != CatalogCreate || plan.Changes[0].Key != "color" {
+		t.Fatalf("Apply() = %+v, %v", plan, err)
+	}
+	if plan, err := reconciler.Plan(catalog); err != nil || !plan.Empty() {
+		t.Errorf("Plan() after Apply() = %s, %v", plan, err)
+	}
+}
+
+func TestCatalogReconciler_ApplyItemErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products/categories", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/categories/batch", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"create":[{"id":0,"error":{"code":"term_exists","message":"A term with the name provided already exists.","data":{"status":400}}}]}`))
+	})
+	reconciler := NewCatalogReconciler(newTestClient(t, mux))
+	catalog := &Catalog{Categories: []CatalogCategory{
+		{ProductCategory: ProductCategory{Name: "Apparel", Slug: "apparel"}},
+		{ProductCategory: ProductCategory{Name: "Men", Slug: "men"}, ParentSlug: "apparel"},
+	}}
+
+	_, err := reconciler.Apply(catalog)
+	var itemErr *BatchItemError
+	if !errors.As(err, &itemErr) || itemErr.Code != "term_exists" || !strings.Contains(err.Error(), `category "apparel"`) {
+		t.Errorf("Apply() err = %v", err)
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/chenyangguang/woocommerce"
+	"gopkg.in/yaml.v3"
+)
+
+const perPage = 100
+
+var (
+	orderColumns   = []string{"id", "number", "status", "date_created", "total", "currency", "billing.email"}
+	productColumns = []string{"id", "sku", "name", "type", "status", "regular_price", "sale_price", "stock_status", "stock_quantity"}
+)
+
+func (c *cli) ordersList(args []string) error {
+	var common commonFlags
+	var status, after, before, columns string
+	var customer int64
+	var limit int
+	fs := flag.NewFlagSet("woo orders list", flag.ContinueOnError)
+	fs.StringVar(&status, "status", "", "comma separated statuses, e.g. processing,on-hold")
+	fs.Int64Var(&customer, "customer", 0, "customer ID")
+	fs.StringVar(&after, "after", "", "only orders created after this ISO8601 date")
+	fs.StringVar(&before, "before", "", "only orders created before this ISO8601 date")
+	fs.IntVar(&limit, "limit", 0, "stop after this many orders, 0 for all")
+	fs.StringVar(&columns, "columns", strings.Join(orderColumns, ","), "table and CSV columns")
+	if err := c.parse(fs, &common, args); err != nil {
+		return err
+	}
+	client, err := c.client(&common)
+	if err != nil {
+		return err
+	}
+
+	options := woocommerce.OrderListOption{Customer: customer}
+	options.After, options.Before, options.PerPage = after, before, perPage
+	if status != "" {
+		options.Status = strings.Split(status, ",")
+	}
+	var orders []woocommerce.Order
+	for options.Page = 1; ; options.Page++ {
+		page, err := client.Order.List(options)
+		if err != nil {
+			return err
+		}
+		orders = append(orders, page...)
+		if limit > 0 && len(orders) >= limit {
+			orders = orders[:limit]
+			break
+		}
+		if len(page) < perPage {
+			break
+		}
+	}
+	if orders == nil {
+		orders = []woocommerce.Order{}
+	}
+	return c.printer(&common, strings.Split(columns, ",")...).print(orders)
+}
+
+func (c *cli) productsGet(args []string) error {
+	var common commonFlags
+	var id int64
+	var sku string
+	fs := flag.NewFlagSet("woo products get", flag.ContinueOnError)
+	fs.Int64Var(&id, "id", 0, "product ID")
+	fs.StringVar(&sku, "sku", "", "product or variation SKU")
+	if err := c.parse(fs, &common, args); err != nil {
+		return err
+	}
+	if (id == 0) == (sku == "") {
+		fmt.Fprintln(c.stderr, "one of --id or --sku is required")
+		return errUsage
+	}
+	client, err := c.client(&common)
+	if err != nil {
+		return err
+	}
+
+	if sku != "" {
+		ref, err := woocommerce.NewSKUCache(client, 0).Resolve(sku)
+		if err != nil {
+			return err
+		}
+		if ref.VariationID != 0 {
+			variation, err := client.ProductVariation.Get(ref.ProductID, ref.VariationID, nil)
+			if err != nil {
+				return err
+			}
+			return c.printer(&common, productColumns...).print(variation)
+		}
+		id = ref.ProductID
+	}
+	product, err := client.Product.Get(id, nil)
+	if err != nil {
+		return err
+	}
+	return c.printer(&common, productColumns...).print(product)
+}
+
+// fieldValues collects repeated --set field=value flags
+type fieldValues map[string]interface{}
+
+func (f fieldValues) String() string {
+	return fmt.Sprint(map[string]interface{}(f))
+}
+
+// Set keeps values as strings, which WooCommerce converts to the field's type,
+// except JSON objects and arrays, e.g. --set 'categories=[{"id":9}]'
+func (f fieldValues) Set(s string) error {
+	field, value, ok := strings.Cut(s, "=")
+	if !ok || field == "" {
+		return fmt.Errorf("%q is not field=value", s)
+	}
+	if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
+		var v interface{}
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return fmt.Errorf("field %s: %w", field, err)
+		}
+		f[field] = v
+		return nil
+	}
+	f[field] = value
+	return nil
+}
+
+func (c *cli) productsUpdate(args []string) error {
+	var common commonFlags
+	var id int64
+	fields := fieldValues{}
+	fs := flag.NewFlagSet("woo products update", flag.ContinueOnError)
+	fs.Int64Var(&id, "id", 0, "product ID")
+	fs.Var(fields, "set", "field=value to update, repeatable")
+	if err := c.parse(fs, &common, args); err != nil {
+		return err
+	}
+	if id == 0 || len(fields) == 0 {
+		fmt.Fprintln(c.stderr, "--id and at least one --set are required")
+		return errUsage
+	}
+	client, err := c.client(&common)
+	if err != nil {
+		return err
+	}
+
+	var product woocommerce.Product
+	if err := client.Put(fmt.Sprintf("products/%d", id), fields, &product); err != nil {
+		return err
+	}
+	return c.printer(&common, productColumns...).print(&product)
+}
+
+// loadWebhooks reads a list of webhooks, either at the top of the file or under
+// a "webhooks" key, using the field names of the REST API
+func loadWebhooks(path string) ([]woocommerce.Webhook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if object, ok := document.(map[string]interface{}); ok {
+		document = object["webhooks"]
+	}
+	// round trip through JSON so that the API field names and types apply
+	data, err = json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var webhooks []woocommerce.Webhook
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return webhooks, nil
+}
+
+// webhookChange is a row of the sync report
+type webhookChange struct {
+	Action string                   `json:"action"`
+	ID     int64                    `json:"id,omitempty"`
+	Name   string                   `json:"name"`
+	Topic  woocommerce.WebhookTopic `json:"topic"`
+	URL    string                   `json:"delivery_url"`
+	Fields string                   `json:"fields,omitempty"`
+}
+
+func (c *cli) webhooksSync(args []string) error {
+	var common commonFlags
+	var file string
+	var options woocommerce.WebhookReconcileOption
+	fs := flag.NewFlagSet("woo webhooks sync", flag.ContinueOnError)
+	fs.StringVar(&file, "f", "", "YAML file of the desired webhooks")
+	fs.BoolVar(&options.DryRun, "dry-run", false, "only show what would change")
+	fs.BoolVar(&options.KeepStale, "keep-stale", false, "keep webhooks missing from the file")
+	fs.BoolVar(&options.RotateSecrets, "rotate-secrets", false, "send the secrets of the file to the existing webhooks")
+	if err := c.parse(fs, &common, args); err != nil {
+		return err
+	}
+	if file == "" {
+		fmt.Fprintln(c.stderr, "-f is required")
+		return errUsage
+	}
+	webhooks, err := loadWebhooks(file)
+	if err != nil {
+		return err
+	}
+	client, err := c.client(&common)
+	if err != nil {
+		return err
+	}
+
+	report, err := client.Webhook.Reconcile(webhooks, options)
+	if err != nil {
+		return err
+	}
+	changes := []webhookChange{}
+	add := func(action string, webhook woocommerce.Webhook, fields []string) {
+		changes = append(changes, webhookChange{
+			Action: action,
+			ID:     webhook.ID,
+			Name:   webhook.Name,
+			Topic:  webhook.Topic,
+			URL:    webhook.DeliveryUrl,
+			Fields: strings.Join(fields, ","),
+		})
+	}
+	for _, webhook := range report.Created {
+		add("create", webhook, nil)
+	}
+	for _, update := range report.Updated {
+		add("update", update.Webhook, update.Fields)
+	}
+	for _, webhook := range report.Deleted {
+		add("delete", webhook, nil)
+	}
+	for _, webhook := range report.Unchanged {
+		add("unchanged", webhook, nil)
+	}
+	return c.printer(&common, "action", "id", "name", "topic", "delivery_url", "fields").print(changes)
+}
+
+// setting is a row of the system status table
+type setting struct {
+	Section string      `json:"section"`
+	Name    string      `json:"name"`
+	Value   interface{} `json:"value"`
+}
+
+func (c *cli) systemStatus(args []string) error {
+	var common commonFlags
+	fs := flag.NewFlagSet("woo system-status", flag.ContinueOnError)
+	if err := c.parse(fs, &common, args); err != nil {
+		return err
+	}
+	client, err := c.client(&common)
+	if err != nil {
+		return err
+	}
+
+	var status map[string]interface{}
+	if err := client.Get("system_status", &status, nil); err != nil {
+		return err
+	}
+	if common.output == formatJSON {
+		return c.printer(&common).print(status)
+	}
+	// tables show the scalar settings of the environment, database and settings sections
+	var settings []setting
+	for _, section := range []string{"environment", "database", "settings"} {
+		values, _ := status[section].(map[string]interface{})
+		names := make([]string, 0, len(values))
+		for name, value := range values {
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				continue
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			settings = append(settings, setting{Section: section, Name: name, Value: values[name]})
+		}
+	}
+	if settings == nil {
+		return errors.New("empty system status")
+	}
+	return c.printer(&common, "section", "name", "value").print(settings)
+}
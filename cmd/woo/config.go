@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile holds the credentials of one store
+type Profile struct {
+	Shop           string `yaml:"shop"`
+	ConsumerKey    string `yaml:"consumer_key"`
+	ConsumerSecret string `yaml:"consumer_secret"`
+	Version        string `yaml:"version"`
+}
+
+// Config is the content of the config file, ~/.config/woo/config.yaml by default:
+//
+//	default: eu
+//	profiles:
+//	  eu:
+//	    shop: eu.example.com
+//	    consumer_key: ck_xxx
+//	    consumer_secret: cs_xxx
+type Config struct {
+	Default  string             `yaml:"default"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+func defaultConfigPath(getenv func(string) string) string {
+	if path := getenv("WOO_CONFIG"); path != "" {
+		return path
+	}
+	dir := getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir = filepath.Join(getenv("HOME"), ".config")
+	}
+	return filepath.Join(dir, "woo", "config.yaml")
+}
+
+func loadConfig(path string) (*Config, error) {
+	config := &Config{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// resolveProfile picks the profile by name, WOO_PROFILE or the config's
+// default, then applies the WOO_SHOP, WOO_CONSUMER_KEY and WOO_CONSUMER_SECRET
+// environment variables over it
+func resolveProfile(config *Config, name string, getenv func(string) string) (Profile, error) {
+	if name == "" {
+		name = getenv("WOO_PROFILE")
+	}
+	if name == "" {
+		name = config.Default
+	}
+	var profile Profile
+	if name != "" {
+		var ok bool
+		if profile, ok = config.Profiles[name]; !ok {
+			return profile, fmt.Errorf("unknown profile %q", name)
+		}
+	}
+	if shop := getenv("WOO_SHOP"); shop != "" {
+		profile.Shop = shop
+	}
+	if key := getenv("WOO_CONSUMER_KEY"); key != "" {
+		profile.ConsumerKey = key
+	}
+	if secret := getenv("WOO_CONSUMER_SECRET"); secret != "" {
+		profile.ConsumerSecret = secret
+	}
+	if profile.Shop == "" || profile.ConsumerKey == "" || profile.ConsumerSecret == "" {
+		return profile, errors.New("no store configured, set up a profile or WOO_SHOP, WOO_CONSUMER_KEY and WOO_CONSUMER_SECRET")
+	}
+	return profile, nil
+}
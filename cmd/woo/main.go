@@ -0,0 +1,153 @@
+// Command woo is a command-line client for the WooCommerce REST API.
+//
+//	woo orders list --status processing --json
+//	woo products get --sku MUG-RED
+//	woo products update --id 12 --set regular_price=9.99
+//	woo webhooks sync -f hooks.yaml
+//	woo system-status
+//
+// Stores are read from profiles in ~/.config/woo/config.yaml, or from the
+// WOO_SHOP, WOO_CONSUMER_KEY and WOO_CONSUMER_SECRET environment variables.
+//
+// Install it with
+//
+//	go install github.com/chenyangguang/woocommerce/cmd/woo@latest
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/chenyangguang/woocommerce"
+)
+
+const usage = `usage: woo <command> [flags]
+
+commands:
+  orders list         list orders, all pages
+  products get        show a product by --id, or a product or variation by --sku
+  products update     update a product with --set field=value
+  webhooks sync       make the store's webhooks match a YAML file
+  system-status       show the store's environment
+
+common flags:
+  --profile name      store profile from the config file
+  --config path       config file, $WOO_CONFIG or ~/.config/woo/config.yaml
+  -o, --output format table, json or csv
+  --json              same as --output json
+`
+
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+	// newClient builds the API client of a profile, replaced in tests
+	newClient func(profile Profile) *woocommerce.Client
+}
+
+// commonFlags are accepted by every command
+type commonFlags struct {
+	profile string
+	config  string
+	output  string
+	json    bool
+}
+
+func (f *commonFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.profile, "profile", "", "store profile")
+	fs.StringVar(&f.config, "config", "", "config file")
+	fs.StringVar(&f.output, "output", formatTable, "output format: table, json or csv")
+	fs.StringVar(&f.output, "o", formatTable, "output format: table, json or csv")
+	fs.BoolVar(&f.json, "json", false, "output JSON")
+}
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	c := &cli{stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv, newClient: newClient}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+func newClient(profile Profile) *woocommerce.Client {
+	opts := []woocommerce.Option{woocommerce.WithLog(&woocommerce.LeveledLogger{Level: woocommerce.LevelWarn})}
+	if profile.Version != "" {
+		opts = append(opts, woocommerce.WithVersion(profile.Version))
+	}
+	app := woocommerce.App{CustomerKey: profile.ConsumerKey, CustomerSecret: profile.ConsumerSecret}
+	return woocommerce.NewClient(app, profile.Shop, append(opts, woocommerce.WithRetry(3))...)
+}
+
+func (c *cli) run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(c.stdout, usage)
+		return 0
+	}
+
+	commands := map[string]func(args []string) error{
+		"orders list":     c.ordersList,
+		"products get":    c.productsGet,
+		"products update": c.productsUpdate,
+		"webhooks sync":   c.webhooksSync,
+		"system-status":   c.systemStatus,
+	}
+	name, rest := args[0], args[1:]
+	if _, ok := commands[name]; !ok && len(args) > 1 {
+		name, rest = args[0]+" "+args[1], args[2:]
+	}
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(c.stderr, "woo: unknown command %q\n\n%s", strings.Join(args, " "), usage)
+		return 2
+	}
+
+	if err := command(rest); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintf(c.stderr, "woo %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// parse parses the flags of a command, reporting errors on stderr
+func (c *cli) parse(fs *flag.FlagSet, common *commonFlags, args []string) error {
+	common.register(fs)
+	fs.SetOutput(c.stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(c.stderr, "unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return errUsage
+	}
+	if common.json {
+		common.output = formatJSON
+	}
+	return nil
+}
+
+// client builds the API client of the selected profile
+func (c *cli) client(common *commonFlags) (*woocommerce.Client, error) {
+	path := common.config
+	if path == "" {
+		path = defaultConfigPath(c.getenv)
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := resolveProfile(config, common.profile, c.getenv)
+	if err != nil {
+		return nil, err
+	}
+	return c.newClient(profile), nil
+}
+
+func (c *cli) printer(common *commonFlags, columns ...string) *printer {
+	return &printer{out: c.stdout, format: common.output, columns: columns}
+}
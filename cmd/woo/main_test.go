@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chenyangguang/woocommerce"
+)
+
+func newTestCLI(t *testing.T, handler http.Handler, env map[string]string) (*cli, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	env["WOO_CONFIG"] = filepath.Join(t.TempDir(), "config.yaml")
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(key string) string { return env[key] },
+		newClient: func(profile Profile) *woocommerce.Client {
+			if profile.Shop != "eu.example.com" {
+				t.Errorf("profile = %+v", profile)
+			}
+			client := woocommerce.NewClient(woocommerce.App{CustomerKey: profile.ConsumerKey, CustomerSecret: profile.ConsumerSecret},
+				strings.TrimPrefix(server.URL, "https://"))
+			client.Client = server.Client()
+			return client
+		},
+	}
+	return c, &stdout, &stderr
+}
+
+func TestOrdersList(t *testing.T) {
+	var pages []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/orders", func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.Query().Get("page"))
+		if r.URL.Query().Get("status") != "processing" {
+			t.Errorf("status = %q", r.URL.Query().Get("status"))
+		}
+		orders := []map[string]interface{}{}
+		if r.URL.Query().Get("page") == "1" {
+			for i := 1; i <= perPage; i++ {
+				orders = append(orders, map[string]interface{}{"id": i, "status": "processing", "total": "10.00"})
+			}
+		} else {
+			orders = append(orders, map[string]interface{}{"id": 101, "status": "processing", "total": "9.50", "billing": map[string]string{"email": "a@example.com"}})
+		}
+		json.NewEncoder(w).Encode(orders)
+	})
+	env := map[string]string{"WOO_SHOP": "eu.example.com", "WOO_CONSUMER_KEY": "ck", "WOO_CONSUMER_SECRET": "cs"}
+
+	c, stdout, stderr := newTestCLI(t, mux, env)
+	if code := c.run([]string{"orders", "list", "--status", "processing", "--json"}); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	var orders []woocommerce.Order
+	if err := json.Unmarshal(stdout.Bytes(), &orders); err != nil || len(orders) != 101 {
+		t.Errorf("listed %d orders, %v", len(orders), err)
+	}
+	if strings.Join(pages, ",") != "1,2" {
+		t.Errorf("fetched pages %v", pages)
+	}
+
+	c, stdout, _ = newTestCLI(t, mux, env)
+	c.run([]string{"orders", "list", "--status", "processing", "-o", "csv", "--columns", "id,total,billing.email"})
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 102 || lines[0] != "id,total,billing.email" || lines[101] != "101,9.50,a@example.com" {
+		t.Errorf("CSV output starts with %q and ends with %q", lines[0], lines[len(lines)-1])
+	}
+}
+
+func TestProductsUpdate(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products/12", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPut || string(body) != `{"categories":[{"id":9}],"regular_price":"9.99"}` {
+			t.Errorf("%s %s", r.Method, body)
+		}
+		w.Write([]byte(`{"id":12,"sku":"MUG","name":"Mug","regular_price":"9.99"}`))
+	})
+	config := filepath.Join(t.TempDir(), "woo.yaml")
+	os.WriteFile(config, []byte("default: eu\nprofiles:\n  eu:\n    shop: eu.example.com\n    consumer_key: ck\n    consumer_secret: cs\n"), 0o600)
+
+	c, stdout, stderr := newTestCLI(t, mux, map[string]string{})
+	code := c.run([]string{"products", "update", "--config", config, "--id", "12", "--set", "regular_price=9.99", "--set", `categories=[{"id":9}]`})
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[1], "9.99") {
+		t.Errorf("table output = %q", stdout)
+	}
+
+	c, _, stderr = newTestCLI(t, mux, map[string]string{})
+	if code := c.run([]string{"products", "update", "--config", config, "--id", "12"}); code != 2 || !strings.Contains(stderr.String(), "--set") {
+		t.Errorf("update without fields: exit code %d, %s", code, stderr)
+	}
+}
+
+func TestProductsGetBySKU(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sku") == "MUG-RED" {
+			w.Write([]byte(`[{"id":71,"type":"variation","parent_id":7,"sku":"MUG-RED"}]`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/7/variations/71", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":71,"sku":"MUG-RED","regular_price":"12.00"}`))
+	})
+	env := map[string]string{"WOO_SHOP": "eu.example.com", "WOO_CONSUMER_KEY": "ck", "WOO_CONSUMER_SECRET": "cs"}
+
+	c, stdout, stderr := newTestCLI(t, mux, env)
+	if code := c.run([]string{"products", "get", "--sku", "MUG-RED", "--json"}); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	var variation woocommerce.ProductVariation
+	if err := json.Unmarshal(stdout.Bytes(), &variation); err != nil || variation.ID != 71 || variation.RegularPrice != "12.00" {
+		t.Errorf("variation = %+v, %v", variation, err)
+	}
+
+	c, _, stderr = newTestCLI(t, mux, env)
+	if code := c.run([]string{"products", "get", "--sku", "NOPE"}); code == 0 || !strings.Contains(stderr.String(), "sku not found") {
+		t.Errorf("unknown SKU: exit code %d, %s", code, stderr)
+	}
+}
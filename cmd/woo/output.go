@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// printer writes API objects in the format chosen on the command line. Table
+// and CSV output show the given columns, dotted paths reach into nested
+// objects, e.g. "billing.email". JSON output shows whole objects.
+type printer struct {
+	out     io.Writer
+	format  string
+	columns []string
+}
+
+func (p *printer) print(v interface{}) error {
+	if p.format == formatJSON {
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	rows, err := toRows(v)
+	if err != nil {
+		return err
+	}
+	records := [][]string{p.columns}
+	for _, row := range rows {
+		record := make([]string, len(p.columns))
+		for i, column := range p.columns {
+			record[i] = cell(lookup(row, column))
+		}
+		records = append(records, record)
+	}
+
+	switch p.format {
+	case formatCSV:
+		w := csv.NewWriter(p.out)
+		w.WriteAll(records)
+		return w.Error()
+	case formatTable:
+		w := tabwriter.NewWriter(p.out, 0, 4, 2, ' ', 0)
+		for i, record := range records {
+			if i == 0 {
+				for j := range record {
+					record[j] = strings.ToUpper(record[j])
+				}
+			}
+			fmt.Fprintln(w, strings.Join(record, "\t"))
+		}
+		return w.Flush()
+	}
+	return fmt.Errorf("unknown output format %q, use table, json or csv", p.format)
+}
+
+// toRows turns an object or a slice of objects into generic JSON objects
+func toRows(v interface{}) ([]map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if bytes.HasPrefix(data, []byte("[")) {
+		var rows []map[string]interface{}
+		return rows, decoder.Decode(&rows)
+	}
+	var row map[string]interface{}
+	if err := decoder.Decode(&row); err != nil {
+		return nil, err
+	}
+	return []map[string]interface{}{row}, nil
+}
+
+func lookup(row map[string]interface{}, path string) interface{} {
+	var value interface{} = row
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func cell(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number, bool:
+		return fmt.Sprint(value)
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
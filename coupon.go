@@ -8,6 +8,25 @@ const (
 	couponsBasePath = "coupons"
 )
 
+// DiscountType is the kind of discount a coupon grants
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#coupon-properties
+type DiscountType string
+
+const (
+	DiscountTypePercent      DiscountType = "percent"
+	DiscountTypeFixedCart    DiscountType = "fixed_cart"
+	DiscountTypeFixedProduct DiscountType = "fixed_product"
+)
+
+// Valid reports whether d is one of WooCommerce's built-in discount types
+func (d DiscountType) Valid() bool {
+	switch d {
+	case DiscountTypePercent, DiscountTypeFixedCart, DiscountTypeFixedProduct:
+		return true
+	}
+	return false
+}
+
 type CouponService interface {
 	Create(coupon Coupon) (*Coupon, error)
 	Get(couponID int64, options interface{}) (*Coupon, error)
@@ -17,31 +36,42 @@ type CouponService interface {
 	Batch(data CouponBatchOption) (*CouponBatchResource, error)
 }
 
+// Coupon represent a WooCommerce coupon
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#coupon-properties
 type Coupon struct {
-	ID                int64                   `json:"id,omitempty"`
-	Code              string                  `json:"code,omitempty"`
-	Amount            string                  `json:"amount,omitempty"`
-	DateCreated       string                  `json:"date_created,omitempty"`
-	DateCreatedGmt    string                  `json:"date_created_gmt,omitempty"`
-	DateModified      string                  `json:"date_modified,omitempty"`
-	DateModifiedGmt   string                  `json:"date_modified_gmt,omitempty"`
-	DiscountType      string                  `json:"discount_type,omitempty"`
-	Description       string                  `json:"description,omitempty"`
-	ExcludeSaleItems  bool                    `json:"exclude_sale_items,omitempty"`
-	ExpiryDate        string                  `json:"expiry_date,omitempty"`
-	FreeShipping      bool                    `json:"free_shipping,omitempty"`
-	IndividualUse     bool                    `json:"individual_use,omitempty"`
-	Length            int                     `json:"length,omitempty"`
-	Limit             int64                   `json:"limit,omitempty"`
-	MinimumAmount     string                  `json:"minimum_amount,omitempty"`
-	MaximumAmount     string                  `json:"maximum_amount,omitempty"`
-	UsageCount        int64                   `json:"usage_count,omitempty"`
-	UsedBy            []string                `json:"used_by,omitempty"`
-	EmailRestrictions CouponEmailRestrictions `json:"email_restrictions,omitempty"`
-}
-
-type CouponEmailRestrictions struct {
-	Emails []string `json:"emails,omitempty"`
+	ID                        int64        `json:"id,omitempty"`
+	Code                      string       `json:"code,omitempty"`
+	Amount                    string       `json:"amount,omitempty"`
+	DateCreated               string       `json:"date_created,omitempty"`
+	DateCreatedGmt            string       `json:"date_created_gmt,omitempty"`
+	DateModified              string       `json:"date_modified,omitempty"`
+	DateModifiedGmt           string       `json:"date_modified_gmt,omitempty"`
+	DiscountType              DiscountType `json:"discount_type,omitempty"`
+	Description               string       `json:"description,omitempty"`
+	DateExpires               string       `json:"date_expires,omitempty"`
+	DateExpiresGmt            string       `json:"date_expires_gmt,omitempty"`
+	UsageCount                int64        `json:"usage_count,omitempty"`
+	IndividualUse             bool         `json:"individual_use,omitempty"`
+	ProductIDs                []int64      `json:"product_ids,omitempty"`
+	ExcludedProductIDs        []int64      `json:"excluded_product_ids,omitempty"`
+	UsageLimit                int64        `json:"usage_limit,omitempty"`
+	UsageLimitPerUser         int64        `json:"usage_limit_per_user,omitempty"`
+	LimitUsageToXItems        int64        `json:"limit_usage_to_x_items,omitempty"`
+	FreeShipping              bool         `json:"free_shipping,omitempty"`
+	ProductCategories         []int64      `json:"product_categories,omitempty"`
+	ExcludedProductCategories []int64      `json:"excluded_product_categories,omitempty"`
+	ExcludeSaleItems          bool         `json:"exclude_sale_items,omitempty"`
+	MinimumAmount             string       `json:"minimum_amount,omitempty"`
+	MaximumAmount             string       `json:"maximum_amount,omitempty"`
+	EmailRestrictions         []string     `json:"email_restrictions,omitempty"`
+	UsedBy                    []string     `json:"used_by,omitempty"`
+	MetaData                  []MetaData   `json:"meta_data,omitempty"`
+	// Deprecated: expiry_date is the legacy v1 name, use DateExpires.
+	ExpiryDate string `json:"expiry_date,omitempty"`
+	Length     int    `json:"length,omitempty"`
+	Limit      int64  `json:"limit,omitempty"`
+	// Error is set on the items of a batch response WooCommerce rejected.
+	Error *BatchItemError `json:"error,omitempty"`
 }
 
 // CouponListOption list all the coupon list option request params
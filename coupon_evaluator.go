@@ -0,0 +1,306 @@
+package woocommerce
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// wooDateLayout is the layout of the ISO8601 dates WooCommerce returns, without zone
+const wooDateLayout = "2006-01-02T15:04:05"
+
+// CartItem is a line of a prospective order together with the product data
+// needed to decide whether a coupon applies to it. For variations, Product is
+// the variation's data with ParentID set to the variable product.
+type CartItem struct {
+	LineItem LineItem
+	Product  Product
+}
+
+// CouponDiscount is the predicted outcome of applying a coupon to a cart
+type CouponDiscount struct {
+	// Discount is the total discount, excluding tax.
+	Discount float64
+	// LineDiscounts holds the discount given to each cart item, by cart index.
+	LineDiscounts []float64
+	FreeShipping  bool
+}
+
+// CouponNotApplicableError explains why a coupon would be refused by WooCommerce
+type CouponNotApplicableError struct {
+	Code    string
+	Message string
+}
+
+func (e CouponNotApplicableError) Error() string {
+	return fmt.Sprintf("coupon %s: %s", e.Code, e.Message)
+}
+
+// Evaluate predicts whether WooCommerce would accept the coupon for cart and
+// email at time now, mirroring the checks of WC_Discounts, and computes the
+// discount it yields. Amounts are based on the line item subtotals, so they are
+// the discount before tax. A CouponNotApplicableError is returned when the
+// coupon would be refused.
+func (c *Coupon) Evaluate(cart []CartItem, email string, now time.Time) (*CouponDiscount, error) {
+	refuse := func(message string, args ...interface{}) (*CouponDiscount, error) {
+		return nil, CouponNotApplicableError{Code: c.Code, Message: fmt.Sprintf(message, args...)}
+	}
+
+	if expires, ok := c.expiry(); ok && now.After(expires) {
+		return refuse("has expired")
+	}
+	if c.UsageLimit > 0 && c.UsageCount >= c.UsageLimit {
+		return refuse("usage limit has been reached")
+	}
+	if len(c.EmailRestrictions) > 0 && !matchesAnyEmail(email, c.EmailRestrictions) {
+		return refuse("is not valid for %q", email)
+	}
+	if c.UsageLimitPerUser > 0 && email != "" {
+		var used int64
+		for _, usedBy := range c.UsedBy {
+			if strings.EqualFold(usedBy, email) {
+				used++
+			}
+		}
+		if used >= c.UsageLimitPerUser {
+			return refuse("usage limit for %q has been reached", email)
+		}
+	}
+
+	prices := make([]float64, len(cart))
+	var subtotal float64
+	for i, item := range cart {
+		price, err := lineSubtotal(item.LineItem)
+		if err != nil {
+			return nil, err
+		}
+		prices[i] = price
+		subtotal += price
+	}
+
+	minimum, err := parseAmount(c.MinimumAmount)
+	if err != nil {
+		return nil, err
+	}
+	if minimum > 0 && subtotal < minimum {
+		return refuse("the minimum spend is %s", c.MinimumAmount)
+	}
+	maximum, err := parseAmount(c.MaximumAmount)
+	if err != nil {
+		return nil, err
+	}
+	if maximum > 0 && subtotal > maximum {
+		return refuse("the maximum spend is %s", c.MaximumAmount)
+	}
+
+	eligible := make([]bool, len(cart))
+	anyEligible := false
+	for i, item := range cart {
+		eligible[i] = c.validForItem(item)
+		anyEligible = anyEligible || eligible[i]
+	}
+
+	if c.DiscountType == DiscountTypeFixedCart {
+		// cart coupons are refused outright when the cart holds excluded items
+		for _, item := range cart {
+			if c.ExcludeSaleItems && item.Product.OnSale {
+				return refuse("is not valid for sale items")
+			}
+			if c.excludesItem(item) {
+				return refuse("is not applicable to some of the products in the cart")
+			}
+		}
+	}
+	if !anyEligible {
+		return refuse("is not applicable to the selected products")
+	}
+
+	amount, err := parseAmount(c.Amount)
+	if err != nil {
+		return nil, err
+	}
+	result := &CouponDiscount{
+		LineDiscounts: make([]float64, len(cart)),
+		FreeShipping:  c.FreeShipping,
+	}
+
+	switch c.DiscountType {
+	case DiscountTypeFixedCart:
+		var eligibleTotal float64
+		for i := range cart {
+			if eligible[i] {
+				eligibleTotal += prices[i]
+			}
+		}
+		if eligibleTotal == 0 {
+			break
+		}
+		discount := amount
+		if discount > eligibleTotal {
+			discount = eligibleTotal
+		}
+		for i := range cart {
+			if eligible[i] {
+				result.LineDiscounts[i] = roundAmount(discount * prices[i] / eligibleTotal)
+			}
+		}
+	case DiscountTypePercent, DiscountTypeFixedProduct:
+		// limit_usage_to_x_items applies to the most expensive units first
+		order := make([]int, 0, len(cart))
+		for i := range cart {
+			if eligible[i] {
+				order = append(order, i)
+			}
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return unitPrice(cart[order[a]].LineItem, prices[order[a]]) > unitPrice(cart[order[b]].LineItem, prices[order[b]])
+		})
+		remaining := c.LimitUsageToXItems
+		for _, i := range order {
+			quantity := int64(cart[i].LineItem.Quantity)
+			if quantity == 0 {
+				quantity = 1
+			}
+			if c.LimitUsageToXItems > 0 {
+				if remaining <= 0 {
+					break
+				}
+				if quantity > remaining {
+					quantity = remaining
+				}
+				remaining -= quantity
+			}
+			unit := unitPrice(cart[i].LineItem, prices[i])
+			perUnit := amount
+			if c.DiscountType == DiscountTypePercent {
+				perUnit = unit * amount / 100
+			}
+			if perUnit > unit {
+				perUnit = unit
+			}
+			result.LineDiscounts[i] = roundAmount(perUnit * float64(quantity))
+		}
+	default:
+		return refuse("discount type %q is not supported", c.DiscountType)
+	}
+
+	for _, discount := range result.LineDiscounts {
+		result.Discount += discount
+	}
+	result.Discount = roundAmount(result.Discount)
+	return result, nil
+}
+
+// expiry returns the coupon's expiry date, preferring the GMT value
+func (c *Coupon) expiry() (time.Time, bool) {
+	if c.DateExpiresGmt != "" {
+		if t, err := time.ParseInLocation(wooDateLayout, c.DateExpiresGmt, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	if c.DateExpires != "" {
+		if t, err := time.ParseInLocation(wooDateLayout, c.DateExpires, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// validForItem mirrors WC_Coupon::is_valid_for_product
+func (c *Coupon) validForItem(item CartItem) bool {
+	if c.ExcludeSaleItems && item.Product.OnSale {
+		return false
+	}
+	if c.excludesItem(item) {
+		return false
+	}
+	if len(c.ProductIDs) == 0 && len(c.ProductCategories) == 0 {
+		return true
+	}
+	for _, id := range itemProductIDs(item) {
+		if containsID(c.ProductIDs, id) {
+			return true
+		}
+	}
+	for _, category := range item.Product.Categories {
+		if containsID(c.ProductCategories, category.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Coupon) excludesItem(item CartItem) bool {
+	for _, id := range itemProductIDs(item) {
+		if containsID(c.ExcludedProductIDs, id) {
+			return true
+		}
+	}
+	for _, category := range item.Product.Categories {
+		if containsID(c.ExcludedProductCategories, category.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// itemProductIDs returns the product, variation and parent IDs a cart item matches
+func itemProductIDs(item CartItem) []int64 {
+	ids := []int64{item.LineItem.ProductID}
+	if item.LineItem.VariationID != 0 {
+		ids = append(ids, item.LineItem.VariationID)
+	}
+	if item.Product.ID != 0 {
+		ids = append(ids, item.Product.ID)
+	}
+	if item.Product.ParentID != 0 {
+		ids = append(ids, item.Product.ParentID)
+	}
+	return ids
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, candidate := range ids {
+		if candidate == id && id != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// lineSubtotal returns the pre-discount total of a line item
+func lineSubtotal(item LineItem) (float64, error) {
+	if item.SubTotal != "" {
+		return parseAmount(item.SubTotal)
+	}
+	quantity := item.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	return item.Price * float64(quantity), nil
+}
+
+func unitPrice(item LineItem, subtotal float64) float64 {
+	if item.Quantity > 1 {
+		return subtotal / float64(item.Quantity)
+	}
+	return subtotal
+}
+
+// matchesAnyEmail reports whether email matches one of the restrictions, which
+// may contain "*" wildcards as in WooCommerce
+func matchesAnyEmail(email string, restrictions []string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return false
+	}
+	for _, restriction := range restrictions {
+		pattern := strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(restriction)), `\*`, ".*")
+		if matched, _ := regexp.MatchString("^"+pattern+"$", email); matched {
+			return true
+		}
+	}
+	return false
+}
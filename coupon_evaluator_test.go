@@ -0,0 +1,79 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func testCart() []CartItem {
+	return []CartItem{
+		{
+			LineItem: LineItem{ProductID: 10, Quantity: 2, SubTotal: "40.00"},
+			Product:  Product{ID: 10, Categories: []ProductCategoryRef{{ID: 1}}},
+		},
+		{
+			LineItem: LineItem{ProductID: 20, VariationID: 21, Quantity: 1, SubTotal: "60.00"},
+			Product:  Product{ID: 21, ParentID: 20, OnSale: true, Categories: []ProductCategoryRef{{ID: 2}}},
+		},
+	}
+}
+
+func TestCoupon_UnmarshalRestrictions(t *testing.T) {
+	var coupon Coupon
+	data := `{"id":1,"code":"vip","email_restrictions":["*@example.com"],"product_ids":[10],"usage_limit":null,"limit_usage_to_x_items":2,"date_expires_gmt":"2030-01-01T00:00:00"}`
+	if err := json.Unmarshal([]byte(data), &coupon); err != nil {
+		t.Fatalf("decode coupon: %v", err)
+	}
+	if coupon.EmailRestrictions[0] != "*@example.com" || coupon.ProductIDs[0] != 10 || coupon.LimitUsageToXItems != 2 {
+		t.Errorf("coupon = %+v", coupon)
+	}
+}
+
+func TestCoupon_Evaluate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		coupon   Coupon
+		email    string
+		discount float64
+		refused  bool
+	}{
+		{"percent", Coupon{Code: "p10", DiscountType: DiscountTypePercent, Amount: "10"}, "", 10, false},
+		{"percent excluding sale items", Coupon{Code: "p10", DiscountType: DiscountTypePercent, Amount: "10", ExcludeSaleItems: true}, "", 4, false},
+		{"fixed cart", Coupon{Code: "f15", DiscountType: DiscountTypeFixedCart, Amount: "15"}, "", 15, false},
+		{"fixed cart capped", Coupon{Code: "f500", DiscountType: DiscountTypeFixedCart, Amount: "500"}, "", 100, false},
+		{"fixed cart with sale items", Coupon{Code: "f15", DiscountType: DiscountTypeFixedCart, Amount: "15", ExcludeSaleItems: true}, "", 0, true},
+		{"fixed product", Coupon{Code: "fp5", DiscountType: DiscountTypeFixedProduct, Amount: "5"}, "", 15, false},
+		{"fixed product limited items", Coupon{Code: "fp5", DiscountType: DiscountTypeFixedProduct, Amount: "5", LimitUsageToXItems: 1}, "", 5, false},
+		{"variation parent id", Coupon{Code: "p50", DiscountType: DiscountTypePercent, Amount: "50", ProductIDs: []int64{20}}, "", 30, false},
+		{"category", Coupon{Code: "c", DiscountType: DiscountTypePercent, Amount: "50", ProductCategories: []int64{1}}, "", 20, false},
+		{"no eligible product", Coupon{Code: "x", DiscountType: DiscountTypePercent, Amount: "10", ProductIDs: []int64{99}}, "", 0, true},
+		{"expired", Coupon{Code: "old", DiscountType: DiscountTypePercent, Amount: "10", DateExpiresGmt: "2024-01-01T00:00:00"}, "", 0, true},
+		{"usage limit", Coupon{Code: "u", DiscountType: DiscountTypePercent, Amount: "10", UsageLimit: 1, UsageCount: 1}, "", 0, true},
+		{"email allowed", Coupon{Code: "e", DiscountType: DiscountTypePercent, Amount: "10", EmailRestrictions: []string{"*@example.com"}}, "Jo@Example.com", 10, false},
+		{"email refused", Coupon{Code: "e", DiscountType: DiscountTypePercent, Amount: "10", EmailRestrictions: []string{"*@example.com"}}, "jo@other.com", 0, true},
+		{"per user limit", Coupon{Code: "e", DiscountType: DiscountTypePercent, Amount: "10", UsageLimitPerUser: 1, UsedBy: []string{"jo@example.com"}}, "jo@example.com", 0, true},
+		{"minimum spend", Coupon{Code: "m", DiscountType: DiscountTypePercent, Amount: "10", MinimumAmount: "150"}, "", 0, true},
+		{"maximum spend", Coupon{Code: "m", DiscountType: DiscountTypePercent, Amount: "10", MaximumAmount: "50"}, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.coupon.Evaluate(testCart(), tt.email, now)
+			if tt.refused {
+				var notApplicable CouponNotApplicableError
+				if !errors.As(err, &notApplicable) {
+					t.Fatalf("Evaluate() err = %v, want CouponNotApplicableError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Evaluate() err = %v", err)
+			}
+			if result.Discount != tt.discount {
+				t.Errorf("Evaluate() discount = %v, want %v (lines %v)", result.Discount, tt.discount, result.LineDiscounts)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package woocommerce
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultCouponCharset leaves out characters that are easily confused, such as 0/O and 1/I
+	DefaultCouponCharset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	defaultCouponBatchSize = 100
+	maxCouponCollisions    = 1000
+
+	// couponCodeExistsError is the code of the batch item error for a taken code
+	couponCodeExistsError = "woocommerce_rest_coupon_code_already_exists"
+)
+
+var couponCSVHeader = []string{"id", "code", "amount", "discount_type", "date_expires"}
+
+// CouponCodePattern describes how coupon codes are generated: Prefix followed by
+// Length random characters drawn from Charset and, with Checksum, a trailing
+// Luhn mod N check character over the random part.
+type CouponCodePattern struct {
+	Prefix   string
+	Charset  string
+	Length   int
+	Checksum bool
+}
+
+func (p CouponCodePattern) charset() string {
+	if p.Charset == "" {
+		return DefaultCouponCharset
+	}
+	return p.Charset
+}
+
+// Generate returns a new random code following the pattern
+func (p CouponCodePattern) Generate() (string, error) {
+	charset := p.charset()
+	if p.Length < 1 {
+		return "", errors.New("coupon code pattern length must be positive")
+	}
+	body := make([]byte, p.Length)
+	max := big.NewInt(int64(len(charset)))
+	for i := range body {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		body[i] = charset[n.Int64()]
+	}
+	code := string(body)
+	if p.Checksum {
+		check, err := luhnCheckCharacter(code, charset)
+		if err != nil {
+			return "", err
+		}
+		code += string(check)
+	}
+	return p.Prefix + code, nil
+}
+
+// Valid reports whether code could have been produced by the pattern, verifying
+// its check character when Checksum is set.
+func (p CouponCodePattern) Valid(code string) bool {
+	if !strings.HasPrefix(code, p.Prefix) {
+		return false
+	}
+	body := strings.TrimPrefix(code, p.Prefix)
+	length := p.Length
+	if p.Checksum {
+		length++
+	}
+	if len(body) != length {
+		return false
+	}
+	charset := p.charset()
+	for i := 0; i < len(body); i++ {
+		if strings.IndexByte(charset, body[i]) < 0 {
+			return false
+		}
+	}
+	if !p.Checksum {
+		return true
+	}
+	check, err := luhnCheckCharacter(body[:len(body)-1], charset)
+	return err == nil && check == body[len(body)-1]
+}
+
+// luhnCheckCharacter computes the Luhn mod N check character of s over charset
+func luhnCheckCharacter(s, charset string) (byte, error) {
+	n := len(charset)
+	factor := 2
+	sum := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		codePoint := strings.IndexByte(charset, s[i])
+		if codePoint < 0 {
+			return 0, fmt.Errorf("character %q is not in the coupon charset", s[i])
+		}
+		addend := factor * codePoint
+		if factor == 2 {
+			factor = 1
+		} else {
+			factor = 2
+		}
+		sum += addend/n + addend%n
+	}
+	return charset[(n-sum%n)%n], nil
+}
+
+// CouponGenerator creates batches of unique single-use coupons sharing a template
+type CouponGenerator struct {
+	coupons  CouponService
+	Pattern  CouponCodePattern
+	Template Coupon
+	// BatchSize is the number of coupons created per Batch call, WooCommerce accepts up to 100.
+	BatchSize int
+}
+
+// NewCouponGenerator returns a CouponGenerator creating coupons through the client's CouponService
+func NewCouponGenerator(c *Client, pattern CouponCodePattern, template Coupon) *CouponGenerator {
+	return &CouponGenerator{
+		coupons:   c.Coupon,
+		Pattern:   pattern,
+		Template:  template,
+		BatchSize: defaultCouponBatchSize,
+	}
+}
+
+// Generate creates count coupons and records them in the CSV file at csvPath.
+// The codes of a batch are recorded without an ID before the batch is sent, then
+// with their ID once created. When the file already holds coupons from an
+// interrupted run, they count towards count and only the remainder is created,
+// so calling Generate again with the same arguments resumes the campaign; codes
+// left without an ID are looked up in the store to learn whether their batch went
+// through. Once count is reached the file is rewritten with the created coupons only.
+// Candidate codes are checked against the existing coupons, listed once with the
+// pattern's prefix as search filter.
+func (g *CouponGenerator) Generate(count int, csvPath string) ([]Coupon, error) {
+	created, pending, err := readCouponsCSV(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := g.existingCoupons()
+	if err != nil {
+		return created, err
+	}
+
+	file, err := os.OpenFile(csvPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return created, err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+	if info, err := file.Stat(); err != nil {
+		return created, err
+	} else if info.Size() == 0 {
+		if err := writer.Write(couponCSVHeader); err != nil {
+			return created, err
+		}
+	}
+
+	// pending codes the store knows were created by the interrupted batch
+	for _, code := range pending {
+		if coupon, ok := existing[strings.ToLower(code)]; ok {
+			created = append(created, coupon)
+			if err := writer.Write(couponCSVRecord(coupon)); err != nil {
+				return created, err
+			}
+		}
+	}
+
+	used := make(map[string]bool, count)
+	for _, coupon := range created {
+		used[strings.ToLower(coupon.Code)] = true
+	}
+
+	batchSize := g.BatchSize
+	if batchSize < 1 || batchSize > defaultCouponBatchSize {
+		batchSize = defaultCouponBatchSize
+	}
+	collisions := 0
+	for len(created) < count {
+		size := count - len(created)
+		if size > batchSize {
+			size = batchSize
+		}
+
+		batch := CouponBatchOption{Create: make([]Coupon, 0, size)}
+		for len(batch.Create) < size {
+			code, err := g.Pattern.Generate()
+			if err != nil {
+				return created, err
+			}
+			key := strings.ToLower(code)
+			if _, taken := existing[key]; taken || used[key] {
+				collisions++
+				if collisions > maxCouponCollisions {
+					return created, fmt.Errorf("gave up after %d code collisions, widen the coupon code pattern", collisions)
+				}
+				continue
+			}
+			used[key] = true
+			coupon := g.Template
+			coupon.ID = 0
+			coupon.Code = code
+			batch.Create = append(batch.Create, coupon)
+			if err := writer.Write(couponCSVRecord(coupon)); err != nil {
+				return created, err
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return created, err
+		}
+
+		resource, err := g.coupons.Batch(batch)
+		if err != nil {
+			return created, err
+		}
+		// results come back in request order
+		var errs []error
+		for i, result := range resource.Create {
+			if result == nil || i >= len(batch.Create) {
+				continue
+			}
+			code := batch.Create[i].Code
+			if result.Error != nil {
+				if result.Error.Code == couponCodeExistsError {
+					// created meanwhile by someone else, another code is drawn
+					existing[strings.ToLower(code)] = Coupon{Code: code}
+					collisions++
+				} else {
+					errs = append(errs, fmt.Errorf("coupon %q: %w", code, result.Error))
+				}
+				continue
+			}
+			coupon := *result
+			if coupon.Code == "" {
+				coupon.Code = code
+			}
+			created = append(created, coupon)
+			if err := writer.Write(couponCSVRecord(coupon)); err != nil {
+				return created, err
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return created, err
+		}
+		if err := errors.Join(errs...); err != nil {
+			return created, err
+		}
+		if collisions > maxCouponCollisions {
+			return created, fmt.Errorf("gave up after %d code collisions, widen the coupon code pattern", collisions)
+		}
+	}
+
+	writer.Flush()
+	if err := file.Close(); err != nil {
+		return created, err
+	}
+	return created, rewriteCouponsCSV(csvPath, created)
+}
+
+// existingCoupons lists the coupons whose code may collide with the pattern's,
+// by lowercased code
+func (g *CouponGenerator) existingCoupons() (map[string]Coupon, error) {
+	coupons, err := listAllPages(func(o ListOptions) ([]Coupon, error) {
+		return g.coupons.List(CouponListOption{ListOptions: o, Search: g.Pattern.Prefix})
+	})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]Coupon, len(coupons))
+	for _, coupon := range coupons {
+		existing[strings.ToLower(coupon.Code)] = coupon
+	}
+	return existing, nil
+}
+
+// WriteCouponsCSV exports coupons as CSV in the same layout CouponGenerator records them
+func WriteCouponsCSV(w io.Writer, coupons []Coupon) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(couponCSVHeader); err != nil {
+		return err
+	}
+	for _, coupon := range coupons {
+		if err := writer.Write(couponCSVRecord(coupon)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func couponCSVRecord(coupon Coupon) []string {
+	return []string{
+		csvInt(coupon.ID),
+		coupon.Code,
+		coupon.Amount,
+		string(coupon.DiscountType),
+		coupon.DateExpires,
+	}
+}
+
+// rewriteCouponsCSV replaces the file at csvPath with coupons
+func rewriteCouponsCSV(csvPath string, coupons []Coupon) error {
+	file, err := os.CreateTemp(filepath.Dir(csvPath), filepath.Base(csvPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err := WriteCouponsCSV(file, coupons); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), csvPath)
+}
+
+// readCouponsCSV loads the coupons recorded by a previous Generate run and the
+// codes it recorded without an ID, a missing file holds none
+func readCouponsCSV(csvPath string) ([]Coupon, []string, error) {
+	file, err := os.Open(csvPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	coupons := make([]Coupon, 0, len(records))
+	pending := map[string]bool{}
+	var pendingOrder []string
+	for i, record := range records {
+		if i == 0 || len(record) < len(couponCSVHeader) {
+			continue
+		}
+		if record[0] == "" {
+			if !pending[record[1]] {
+				pending[record[1]] = true
+				pendingOrder = append(pendingOrder, record[1])
+			}
+			continue
+		}
+		id, err := strconv.ParseInt(record[0], 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s line %d: %w", csvPath, i+1, err)
+		}
+		pending[record[1]] = false
+		coupons = append(coupons, Coupon{
+			ID:           id,
+			Code:         record[1],
+			Amount:       record[2],
+			DiscountType: DiscountType(record[3]),
+			DateExpires:  record[4],
+		})
+	}
+	var codes []string
+	for _, code := range pendingOrder {
+		if pending[code] {
+			codes = append(codes, code)
+		}
+	}
+	return coupons, codes, nil
+}
@@ -0,0 +1,180 @@
+package woocommerce
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCouponCodePattern(t *testing.T) {
+	pattern := CouponCodePattern{Prefix: "SPRING-", Length: 8, Checksum: true}
+	for i := 0; i < 50; i++ {
+		code, err := pattern.Generate()
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		if len(code) != len("SPRING-")+9 || !pattern.Valid(code) {
+			t.Fatalf("generated code %q is not valid", code)
+		}
+		last := code[len(code)-1]
+		tampered := code[:len(code)-1] + string(DefaultCouponCharset[(strings.IndexByte(DefaultCouponCharset, last)+1)%len(DefaultCouponCharset)])
+		if pattern.Valid(tampered) {
+			t.Fatalf("tampered code %q passed the checksum", tampered)
+		}
+	}
+	if _, err := (CouponCodePattern{}).Generate(); err == nil {
+		t.Errorf("zero length pattern should fail")
+	}
+}
+
+func TestCouponGenerator_Generate(t *testing.T) {
+	var mu sync.Mutex
+	nextID := int64(100)
+	batches, lists := 0, 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/coupons", func(w http.ResponseWriter, r *http.Request) {
+		lists++
+		if r.URL.Query().Get("search") != "X" {
+			t.Errorf("coupons searched with %q", r.URL.Query().Get("search"))
+		}
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/wp-json/wc/v3/coupons/batch", func(w http.ResponseWriter, r *http.Request) {
+		var batch CouponBatchOption
+		json.NewDecoder(r.Body).Decode(&batch)
+		mu.Lock()
+		defer mu.Unlock()
+		batches++
+		resource := CouponBatchResource{}
+		for _, coupon := range batch.Create {
+			nextID++
+			coupon.ID = nextID
+			resource.Create = append(resource.Create, &coupon)
+		}
+		json.NewEncoder(w).Encode(resource)
+	})
+	c := newTestClient(t, mux)
+
+	csvPath := filepath.Join(t.TempDir(), "campaign.csv")
+	generator := NewCouponGenerator(c, CouponCodePattern{Prefix: "X", Length: 6}, Coupon{Amount: "5", DiscountType: DiscountTypeFixedCart, UsageLimit: 1})
+	generator.BatchSize = 3
+
+	first, err := generator.Generate(4, csvPath)
+	if err != nil || len(first) != 4 || batches != 2 {
+		t.Fatalf("Generate(4) = %d coupons, %d batches, err %v", len(first), batches, err)
+	}
+	if lists != 1 {
+		t.Errorf("listed existing coupons %d times, want once", lists)
+	}
+
+	// a second run with a higher target resumes from the recorded coupons
+	all, err := generator.Generate(5, csvPath)
+	if err != nil || len(all) != 5 || batches != 3 {
+		t.Fatalf("Generate(5) = %d coupons, %d batches, err %v", len(all), batches, err)
+	}
+	if all[0].Code != first[0].Code || all[4].Amount != "5" {
+		t.Errorf("resumed coupons = %+v", all)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCouponsCSV(&buf, all); err != nil {
+		t.Fatalf("WriteCouponsCSV: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 6 {
+		t.Errorf("exported %d lines, want 6", lines)
+	}
+}
+
+func TestCouponGenerator_GenerateResumesPending(t *testing.T) {
+	batches := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/coupons", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]Coupon{{ID: 1, Code: "xaaaaaa"}, {ID: 7, Code: "xbbbbbb", Amount: "5"}})
+	})
+	mux.HandleFunc("/wp-json/wc/v3/coupons/batch", func(w http.ResponseWriter, r *http.Request) {
+		var batch CouponBatchOption
+		json.NewDecoder(r.Body).Decode(&batch)
+		batches++
+		resource := CouponBatchResource{}
+		for i, coupon := range batch.Create {
+			coupon.ID = int64(100 + i)
+			resource.Create = append(resource.Create, &coupon)
+		}
+		json.NewEncoder(w).Encode(resource)
+	})
+	c := newTestClient(t, mux)
+
+	// an interrupted run: XAAAAAA was created, XBBBBBB and XCCCCCC were sent
+	// in a batch whose response was lost, only XBBBBBB went through
+	csvPath := filepath.Join(t.TempDir(), "campaign.csv")
+	os.WriteFile(csvPath, []byte("id,code,amount,discount_type,date_expires\n"+
+		",XAAAAAA,5,fixed_cart,\n1,XAAAAAA,5,fixed_cart,\n,XBBBBBB,5,fixed_cart,\n,XCCCCCC,5,fixed_cart,\n"), 0o644)
+	generator := NewCouponGenerator(c, CouponCodePattern{Prefix: "X", Length: 6}, Coupon{Amount: "5", DiscountType: DiscountTypeFixedCart})
+
+	coupons, err := generator.Generate(3, csvPath)
+	if err != nil || len(coupons) != 3 || batches != 1 {
+		t.Fatalf("Generate(3) = %+v, %d batches, err %v", coupons, batches, err)
+	}
+	if coupons[0].Code != "XAAAAAA" || coupons[1].ID != 7 || coupons[2].ID != 100 {
+		t.Errorf("coupons = %+v", coupons)
+	}
+	written, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(written)), "\n")
+	if len(lines) != 4 || strings.Contains(string(written), "XCCCCCC") || strings.Contains(string(written), "\n,") {
+		t.Errorf("campaign file = %q", written)
+	}
+}
+
+func TestCouponGenerator_GenerateRejected(t *testing.T) {
+	var sent []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/coupons", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/wp-json/wc/v3/coupons/batch", func(w http.ResponseWriter, r *http.Request) {
+		var batch CouponBatchOption
+		json.NewDecoder(r.Body).Decode(&batch)
+		resource := CouponBatchResource{}
+		for _, coupon := range batch.Create {
+			sent = append(sent, coupon.Code)
+			switch {
+			case len(sent) == 1:
+				resource.Create = append(resource.Create, &Coupon{Error: &BatchItemError{Code: "woocommerce_rest_coupon_code_already_exists", Message: "The coupon code already exists"}})
+			case coupon.Amount == "-1":
+				resource.Create = append(resource.Create, &Coupon{Error: &BatchItemError{Code: "rest_invalid_param", Message: "Invalid amount."}})
+			default:
+				coupon.ID = int64(100 + len(sent))
+				resource.Create = append(resource.Create, &coupon)
+			}
+		}
+		json.NewEncoder(w).Encode(resource)
+	})
+	c := newTestClient(t, mux)
+	dir := t.TempDir()
+
+	generator := NewCouponGenerator(c, CouponCodePattern{Prefix: "X", Length: 6}, Coupon{Amount: "5"})
+	generator.BatchSize = 2
+	coupons, err := generator.Generate(2, filepath.Join(dir, "taken.csv"))
+	if err != nil || len(coupons) != 2 || len(sent) != 3 {
+		t.Fatalf("Generate(2) with a taken code = %d coupons, %d sent, err %v", len(coupons), len(sent), err)
+	}
+	for _, coupon := range coupons {
+		if coupon.Code == sent[0] {
+			t.Errorf("rejected code %s reported as created", sent[0])
+		}
+	}
+
+	generator.Template.Amount = "-1"
+	coupons, err = generator.Generate(1, filepath.Join(dir, "invalid.csv"))
+	if err == nil || !strings.Contains(err.Error(), "Invalid amount.") || len(coupons) != 0 {
+		t.Errorf("Generate(1) of invalid coupons = %+v, err %v", coupons, err)
+	}
+}
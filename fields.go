@@ -0,0 +1,28 @@
+package woocommerce
+
+import "encoding/json"
+
+// GetOption is the options of the Get methods, to fetch part of an entity or
+// embed its linked resources
+type GetOption struct {
+	Context string `url:"context,omitempty"`
+	// Fields limits the response to these fields, nested ones as "billing.email".
+	// The other fields of the decoded entity are left to their zero value.
+	Fields []string `url:"_fields,omitempty,comma"`
+	// Embed includes the linked resources in the response, see Embedded.
+	Embed bool `url:"_embed,omitempty"`
+}
+
+// Embedded holds the linked resources of an entity fetched with Embed, by link
+// relation, e.g. "up" for the parent product of a variation
+type Embedded map[string][]json.RawMessage
+
+// Decode decodes the first resource linked by relation into v and reports
+// whether there was one
+func (e Embedded) Decode(relation string, v interface{}) (bool, error) {
+	resources := e[relation]
+	if len(resources) == 0 {
+		return false, nil
+	}
+	return true, json.Unmarshal(resources[0], v)
+}
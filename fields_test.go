@@ -0,0 +1,40 @@
+package woocommerce
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFieldsAndEmbed(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/orders", func(w http.ResponseWriter, r *http.Request) {
+		if fields := r.URL.Query().Get("_fields"); fields != "id,status,date_modified" {
+			t.Errorf("_fields = %q", fields)
+		}
+		w.Write([]byte(`[{"id":1,"status":"processing","date_modified":"2024-05-01T10:00:00"},{"id":2,"status":"completed","date_modified":"2024-05-02T10:00:00"}]`))
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/7/variations/71", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("_embed") != "true" || r.URL.Query().Get("_fields") != "id,sku,_links,_embedded" {
+			t.Errorf("query = %q", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"id":71,"sku":"MUG-RED","_links":{"up":[{"href":"https://shop/wp-json/wc/v3/products/7","embeddable":true}]},"_embedded":{"up":[{"id":7,"name":"Mug"}]}}`))
+	})
+	c := newTestClient(t, mux)
+
+	orders, err := c.Order.List(OrderListOption{ListOptions: ListOptions{Fields: []string{"id", "status", "date_modified"}}})
+	if err != nil || len(orders) != 2 || orders[1].Status != "completed" || orders[1].DateModified != "2024-05-02T10:00:00" {
+		t.Fatalf("List() = %+v, %v", orders, err)
+	}
+
+	variation, err := c.ProductVariation.Get(7, 71, GetOption{Fields: []string{"id", "sku", "_links", "_embedded"}, Embed: true})
+	if err != nil || variation.SKU != "MUG-RED" {
+		t.Fatalf("Get() = %+v, %v", variation, err)
+	}
+	var parent Product
+	if ok, err := variation.Embedded.Decode("up", &parent); !ok || err != nil || parent.Name != "Mug" {
+		t.Errorf("embedded parent = %+v, %v, %v", parent, ok, err)
+	}
+	if ok, _ := variation.Embedded.Decode("customer", &parent); ok {
+		t.Error("Decode() of a missing relation reported a resource")
+	}
+}
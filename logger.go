@@ -22,6 +22,13 @@ type LeveledLoggerInterface interface {
 	Warnf(format string, v ...interface{})
 }
 
+// DebugEnabler is implemented by the loggers that can tell whether they emit
+// debug messages. The client only reads and redacts bodies for logging when
+// DebugEnabled returns true, loggers without the method get them all.
+type DebugEnabler interface {
+	DebugEnabled() bool
+}
+
 // It prints warnings and errors to `os.Stderr` and other messages to
 // `os.Stdout`.
 type LeveledLogger struct {
@@ -39,6 +46,11 @@ type LeveledLogger struct {
 	stdoutOverride io.Writer
 }
 
+// DebugEnabled reports whether Level lets debug messages through.
+func (l *LeveledLogger) DebugEnabled() bool {
+	return l.Level >= LevelDebug
+}
+
 // Debugf logs a debug message using Printf conventions.
 func (l *LeveledLogger) Debugf(format string, v ...interface{}) {
 	if l.Level >= LevelDebug {
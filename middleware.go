@@ -0,0 +1,253 @@
+package woocommerce
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Call is one attempt of an API request going through the middleware chain, or
+// the whole request going through the request middleware chain. Middlewares may
+// change Request, for instance to rewrite its headers.
+type Call struct {
+	Request *http.Request
+	// Method and Path describe the API call, Path is relative to the API prefix, e.g. "products/12".
+	Method string
+	Path   string
+	// Body is the JSON request body, nil for requests without one.
+	Body []byte
+	// Attempt counts from 1, retries of rate limited or unavailable requests get
+	// higher numbers. For a whole request it is the number of attempts made.
+	Attempt int
+	// RateLimitWait is how long the client waited before this attempt because of a
+	// 429 response, or before all the attempts of a whole request.
+	RateLimitWait time.Duration
+}
+
+// Endpoint is Path with its numeric segments replaced by "{id}", e.g.
+// "products/{id}/variations", suitable as a low cardinality label
+func (c *Call) Endpoint() string {
+	segments := strings.Split(c.Path, "/")
+	for i, segment := range segments {
+		if isNumeric(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Resource names the collection the call works on, e.g. "orders" or "products.variations"
+func (c *Call) Resource() string {
+	var names []string
+	for _, segment := range strings.Split(c.Path, "/") {
+		if segment != "" && segment != "batch" && !isNumeric(segment) {
+			names = append(names, segment)
+		}
+	}
+	return strings.Join(names, ".")
+}
+
+// Operation is what the call does to its resource: list, get, create, update,
+// delete or batch
+func (c *Call) Operation() string {
+	path := strings.TrimSuffix(c.Path, "/")
+	if strings.HasSuffix(path, "/batch") || path == "batch" {
+		return "batch"
+	}
+	segments := strings.Split(path, "/")
+	single := isNumeric(segments[len(segments)-1])
+	switch c.Method {
+	case http.MethodGet:
+		if single {
+			return "get"
+		}
+		return "list"
+	case http.MethodPost:
+		if single {
+			return "update"
+		}
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return strings.ToLower(c.Method)
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// CallHandler sends a call and returns the raw response. An error is only returned
+// when no response was received, API errors are reported through the status code.
+type CallHandler func(call *Call) (*http.Response, error)
+
+// Middleware wraps the handling of every call made by the client. A middleware
+// may answer the call itself without calling next, e.g. to serve a cached response.
+type Middleware func(next CallHandler) CallHandler
+
+// WithMiddleware appends middlewares to the client's chain, the first one
+// registered is the outermost
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// CallInfo is what an observer learns about a finished call
+type CallInfo struct {
+	Method        string
+	Path          string
+	Body          []byte
+	Attempt       int
+	RateLimitWait time.Duration
+	Endpoint      string
+	Resource      string
+	Operation     string
+	// Response is nil when Err is set, its body has been read into ResponseBody.
+	Response     *http.Response
+	ResponseBody []byte
+	// ErrorCode is the WooCommerce error code of a failed response, e.g. "woocommerce_rest_product_invalid_id".
+	ErrorCode string
+	Err       error
+	Duration  time.Duration
+}
+
+// ReadResponseBody reads the body of resp and puts an unread copy back, so that
+// middlewares can look at responses without consuming them
+func ReadResponseBody(resp *http.Response) ([]byte, error) {
+	if resp == nil || resp.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// ResponseErrorCode returns the "code" of a WooCommerce error response body, or
+// an empty string when body is not one
+func ResponseErrorCode(body []byte) string {
+	var wooErr struct {
+		Code string `json:"code"`
+	}
+	if json.Unmarshal(body, &wooErr) != nil {
+		return ""
+	}
+	return wooErr.Code
+}
+
+// ObserverMiddleware returns a middleware calling observe after every call, for
+// auditing and metrics that only need to look at calls
+func ObserverMiddleware(observe func(info CallInfo)) Middleware {
+	return func(next CallHandler) CallHandler {
+		return func(call *Call) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(call)
+			info := CallInfo{
+				Method:        call.Method,
+				Path:          call.Path,
+				Body:          call.Body,
+				Attempt:       call.Attempt,
+				RateLimitWait: call.RateLimitWait,
+				Endpoint:      call.Endpoint(),
+				Resource:      call.Resource(),
+				Operation:     call.Operation(),
+				Response:      resp,
+				Err:           err,
+				Duration:      time.Since(start),
+			}
+			if resp != nil {
+				body, readErr := ReadResponseBody(resp)
+				info.ResponseBody = body
+				if readErr != nil && err == nil {
+					info.Err = readErr
+				}
+				if resp.StatusCode >= http.StatusBadRequest {
+					info.ErrorCode = ResponseErrorCode(body)
+				}
+			}
+			observe(info)
+			return resp, err
+		}
+	}
+}
+
+// RequestHandler runs a whole API request, all its attempts included
+type RequestHandler func(call *Call) error
+
+// RequestMiddleware wraps the handling of every request made by the client, from
+// its first attempt to its last retry, where Middleware wraps each attempt. The
+// Call it gets has no attempt made yet: once next returns, Attempt is the number
+// of attempts made and RateLimitWait the total time waited because of 429
+// responses. A Request changed before calling next is the one every attempt
+// starts from, e.g. to carry a context.
+type RequestMiddleware func(next RequestHandler) RequestHandler
+
+// WithRequestMiddleware appends request middlewares to the client's chain, the
+// first one registered is the outermost
+func WithRequestMiddleware(middlewares ...RequestMiddleware) Option {
+	return func(c *Client) {
+		c.requestMiddlewares = append(c.requestMiddlewares, middlewares...)
+	}
+}
+
+// send runs one attempt of req through the middleware chain
+func (c *Client) send(req *http.Request, attempt int, rateLimitWait time.Duration) (*http.Response, error) {
+	call, err := c.newCall(req)
+	if err != nil {
+		return nil, err
+	}
+	call.Attempt = attempt
+	call.RateLimitWait = rateLimitWait
+
+	handler := func(call *Call) (*http.Response, error) {
+		return c.Client.Do(call.Request)
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		handler = c.middlewares[i](handler)
+	}
+	return handler(call)
+}
+
+// newCall describes req for the middlewares
+func (c *Client) newCall(req *http.Request) (*Call, error) {
+	relPath := strings.TrimPrefix(req.URL.Path, c.pathPrefix)
+	if relPath == req.URL.Path {
+		// a complete path to another namespace
+		relPath = strings.TrimPrefix(relPath, apiRootPath)
+	}
+	call := &Call{
+		Request: req,
+		Method:  req.Method,
+		Path:    strings.TrimPrefix(relPath, "/"),
+	}
+	if req.GetBody != nil {
+		// every attempt gets a fresh body, the previous one has been consumed
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		call.Body, err = io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		if len(call.Body) == 0 {
+			call.Body = nil
+		}
+		req.Body = io.NopCloser(bytes.NewReader(call.Body))
+	}
+	return call, nil
+}
@@ -0,0 +1,136 @@
+package woocommerce
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	var bodies []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products/batch", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("X-Tenant") != "eu" {
+			t.Errorf("X-Tenant header = %q", r.Header.Get("X-Tenant"))
+		}
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"code":"unavailable","message":"try again"}`))
+			return
+		}
+		w.Write([]byte(`{"create":[{"id":1}]}`))
+	})
+
+	var order []string
+	var observed []CallInfo
+	trace := func(name string) Middleware {
+		return func(next CallHandler) CallHandler {
+			return func(call *Call) (*http.Response, error) {
+				order = append(order, name)
+				return next(call)
+			}
+		}
+	}
+	tenant := func(next CallHandler) CallHandler {
+		return func(call *Call) (*http.Response, error) {
+			call.Request.Header.Set("X-Tenant", "eu")
+			return next(call)
+		}
+	}
+	c := newTestClient(t, mux, WithRetry(3),
+		WithMiddleware(trace("outer"), trace("inner")),
+		WithMiddleware(tenant, ObserverMiddleware(func(info CallInfo) { observed = append(observed, info) })))
+
+	resource, err := c.Product.Batch(ProductBatchOption{Create: []Product{{Name: "Mug"}}})
+	if err != nil || len(resource.Create) != 1 || resource.Create[0].ID != 1 {
+		t.Fatalf("Batch() = %+v, %v", resource, err)
+	}
+	if strings.Join(order, ",") != "outer,inner,outer,inner" {
+		t.Errorf("middleware order = %v", order)
+	}
+	if len(bodies) != 2 || bodies[0] != bodies[1] || !strings.Contains(bodies[1], "Mug") {
+		t.Errorf("retried request bodies = %q", bodies)
+	}
+	if len(observed) != 2 {
+		t.Fatalf("observed %d calls", len(observed))
+	}
+	first, second := observed[0], observed[1]
+	if first.Method != http.MethodPost || first.Path != "products/batch" || first.Attempt != 1 || first.Response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("first call = %+v", first)
+	}
+	if second.Attempt != 2 || !strings.Contains(string(second.Body), "Mug") || string(second.ResponseBody) != `{"create":[{"id":1}]}` || second.Duration <= 0 {
+		t.Errorf("second call = %+v", second)
+	}
+}
+
+func TestWithMiddleware_ShortCircuit(t *testing.T) {
+	fake := func(next CallHandler) CallHandler {
+		return func(call *Call) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(bytes.NewBufferString(`{"id":7,"name":"cached"}`)),
+				Request:    call.Request,
+			}, nil
+		}
+	}
+	c := newTestClient(t, http.NotFoundHandler(), WithMiddleware(fake))
+
+	product, err := c.Product.Get(7, nil)
+	if err != nil || product.Name != "cached" {
+		t.Errorf("Get() = %+v, %v", product, err)
+	}
+}
+
+func TestWithRequestMiddleware(t *testing.T) {
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/orders/12", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"code":"unavailable","message":"try again"}`))
+			return
+		}
+		w.Write([]byte(`{"id":12}`))
+	})
+
+	type key struct{}
+	var before, after Call
+	var contexts []interface{}
+	request := func(next RequestHandler) RequestHandler {
+		return func(call *Call) error {
+			before = *call
+			call.Request = call.Request.WithContext(context.WithValue(call.Request.Context(), key{}, "request"))
+			err := next(call)
+			after = *call
+			return err
+		}
+	}
+	attempt := func(next CallHandler) CallHandler {
+		return func(call *Call) (*http.Response, error) {
+			contexts = append(contexts, call.Request.Context().Value(key{}))
+			return next(call)
+		}
+	}
+	c := newTestClient(t, mux, WithRetry(3), WithRequestMiddleware(request), WithMiddleware(attempt))
+
+	order, err := c.Order.Get(12, nil)
+	if err != nil || order.ID != 12 {
+		t.Fatalf("Get() = %+v, %v", order, err)
+	}
+	if before.Attempt != 0 || before.Method != http.MethodGet || before.Path != "orders/12" {
+		t.Errorf("call before the attempts = %+v", before)
+	}
+	if after.Attempt != 2 {
+		t.Errorf("call after the attempts made %d attempts, want 2", after.Attempt)
+	}
+	if len(contexts) != 2 || contexts[0] != "request" || contexts[1] != "request" {
+		t.Errorf("attempt context values = %v", contexts)
+	}
+}
@@ -1,6 +1,9 @@
 package woocommerce
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
 const (
 	orderNoteBasePath = "orders"
@@ -10,9 +13,11 @@ const (
 // https://woocommerce.github.io/woocommerce-rest-api-docs/#order-notes
 type OrderNoteService interface {
 	Create(orderId int64, text string) (*OrderNote, error)
+	CreateNote(orderId int64, note OrderNote) (*OrderNote, error)
 	Get(orderId int64, noteId int64) (*OrderNote, error)
 	List(orderId int64, options interface{}) (*[]OrderNote, error)
 	Delete(orderId int64, noteId int64, options interface{}) (*OrderNote, error)
+	ListForOrders(orderIds []int64, options interface{}, concurrency int) (map[int64][]OrderNote, error)
 }
 
 // OrderNote represent a WooCommerce Order note
@@ -23,9 +28,46 @@ type OrderNote struct {
 	DateCreated    string `json:"date_created,omitempty"`
 	DateCreatedGmt string `json:"date_created_gmt,omitempty"`
 
-	Note         string `json:"note,omitempty"`
-	CustomerNote string `json:"customer_note,omitempty"`
-	AddedByUser  bool   `json:"added_by_user,omitempty"`
+	Note string `json:"note,omitempty"`
+	// CustomerNote makes the note visible to the customer, who is emailed a copy.
+	CustomerNote bool `json:"customer_note,omitempty"`
+	// AddedByUser attributes the note to the API user instead of the system.
+	AddedByUser bool `json:"added_by_user,omitempty"`
+}
+
+// OrderNoteType filters order notes by audience
+type OrderNoteType string
+
+const (
+	OrderNoteTypeAny      OrderNoteType = "any"
+	OrderNoteTypeCustomer OrderNoteType = "customer"
+	OrderNoteTypeInternal OrderNoteType = "internal"
+)
+
+// OrderNoteListOption config order note's List method request option
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#list-all-order-notes
+type OrderNoteListOption struct {
+	Context string        `url:"context,omitempty"`
+	Type    OrderNoteType `url:"type,omitempty"`
+	Fields  []string      `url:"_fields,omitempty,comma"`
+}
+
+// OrderNotesError collects the orders whose notes could not be listed by ListForOrders
+type OrderNotesError struct {
+	Errors map[int64]error
+}
+
+func (e OrderNotesError) Error() string {
+	return fmt.Sprintf("listing notes failed for %d orders", len(e.Errors))
+}
+
+// Unwrap returns the individual per order errors
+func (e OrderNotesError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
 }
 
 type OrderNoteServiceOp struct {
@@ -42,6 +84,19 @@ func (n *OrderNoteServiceOp) Create(orderId int64, text string) (*OrderNote, err
 	return resource, err
 }
 
+// CreateNote adds note to an order, honouring its CustomerNote and AddedByUser flags
+func (n *OrderNoteServiceOp) CreateNote(orderId int64, note OrderNote) (*OrderNote, error) {
+	path := fmt.Sprintf("%s/%d/notes", orderNoteBasePath, orderId)
+	resource := new(OrderNote)
+	insertOrderNote := OrderNote{
+		Note:         note.Note,
+		CustomerNote: note.CustomerNote,
+		AddedByUser:  note.AddedByUser,
+	}
+	err := n.client.Post(path, insertOrderNote, resource)
+	return resource, err
+}
+
 func (n *OrderNoteServiceOp) Get(orderId int64, noteId int64) (*OrderNote, error) {
 	path := fmt.Sprintf("%s/%d/notes/%d", orderNoteBasePath, orderId, noteId)
 	resource := new(OrderNote)
@@ -64,3 +119,43 @@ func (n *OrderNoteServiceOp) Delete(orderId int64, noteId int64, options interfa
 	err := n.client.Delete(path, options, &resource)
 	return resource, err
 }
+
+// ListForOrders lists the notes of several orders, running up to concurrency
+// requests at a time. Notes of orders that could be listed are always returned,
+// failures are reported together as an OrderNotesError.
+func (n *OrderNoteServiceOp) ListForOrders(orderIds []int64, options interface{}, concurrency int) (map[int64][]OrderNote, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		notes  = make(map[int64][]OrderNote, len(orderIds))
+		failed = make(map[int64]error)
+		sem    = make(chan struct{}, concurrency)
+	)
+	for _, orderId := range orderIds {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(orderId int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resource, err := n.List(orderId, options)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[orderId] = err
+				return
+			}
+			notes[orderId] = *resource
+		}(orderId)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return notes, OrderNotesError{Errors: failed}
+	}
+	return notes, nil
+}
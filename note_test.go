@@ -0,0 +1,52 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOrderNoteServiceOp_CreateNote(t *testing.T) {
+	var sent map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/orders/5/notes", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"id":9,"note":"Your parcel has shipped","customer_note":true}`))
+	})
+	c := newTestClient(t, mux)
+
+	note, err := c.OrderNote.CreateNote(5, OrderNote{Note: "Your parcel has shipped", CustomerNote: true})
+	if err != nil {
+		t.Fatalf("CreateNote: %v", err)
+	}
+	if sent["customer_note"] != true || !note.CustomerNote {
+		t.Errorf("customer_note not round tripped: sent %v, got %+v", sent, note)
+	}
+}
+
+func TestOrderNoteServiceOp_ListForOrders(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/orders/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("type") != "customer" {
+			t.Errorf("type filter not sent: %s", r.URL.RawQuery)
+		}
+		if strings.HasPrefix(r.URL.Path, "/wp-json/wc/v3/orders/3/") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"woocommerce_rest_shop_order_invalid_id","message":"Invalid ID."}`))
+			return
+		}
+		w.Write([]byte(`[{"id":1,"note":"hello","customer_note":true}]`))
+	})
+	c := newTestClient(t, mux)
+
+	notes, err := c.OrderNote.ListForOrders([]int64{1, 2, 3}, OrderNoteListOption{Type: OrderNoteTypeCustomer}, 2)
+	var notesErr OrderNotesError
+	if !errors.As(err, &notesErr) || len(notesErr.Errors) != 1 || notesErr.Errors[3] == nil {
+		t.Fatalf("ListForOrders err = %v", err)
+	}
+	if len(notes) != 2 || len(notes[1]) != 1 || !notes[2][0].CustomerNote {
+		t.Errorf("ListForOrders notes = %+v", notes)
+	}
+}
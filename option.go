@@ -1,21 +1,44 @@
 package woocommerce
 
-import "fmt"
+import (
+	"fmt"
+	"path"
+	"strings"
+)
 
 type Option func(c *Client)
 
-// WithVersion version config option
+// WithVersion selects the WooCommerce REST API version, "v1", "v2" or "v3" (the
+// default), also accepted as "wc/v2". Other versions are ignored with a warning
+// logged, WithNamespace reaches other namespaces.
 func WithVersion(apiVersion string) Option {
 	return func(c *Client) {
-		pathPrefix := defaultApiPathPrefix
-		if len(apiVersion) > 0 && apiVersionRegex.MatchString(apiVersion) {
-			c.pathPrefix = fmt.Sprintf("%s/%s", defaultApiPathPrefix, apiVersion)
+		version := strings.TrimPrefix(apiVersion, "wc/")
+		if !apiVersionRegex.MatchString(version) {
+			c.unsupportedVersion = apiVersion
+			return
 		}
-		c.version = apiVersion
-		c.pathPrefix = pathPrefix
+		c.unsupportedVersion = ""
+		c.version = version
+		c.pathPrefix = fmt.Sprintf("%s/wc/%s", apiRootPath, version)
 	}
 }
 
+// WithNamespace sends the client's requests to another REST namespace, e.g.
+// "wc/store/v1" or a plugin's "wc-bookings/v1". To reach a namespace once, pass
+// a complete path such as "/wp-json/wc-bookings/v1/bookings" to Get and co.
+func WithNamespace(namespace string) Option {
+	return func(c *Client) {
+		c.pathPrefix = path.Join(apiRootPath, strings.Trim(namespace, "/"))
+		c.version = path.Base(c.pathPrefix)
+	}
+}
+
+// Version returns the REST API version requests are sent to, e.g. "v3"
+func (c *Client) Version() string {
+	return c.version
+}
+
 // WithRetry Timeout config option
 func WithRetry(retries int) Option {
 	return func(c *Client) {
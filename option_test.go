@@ -0,0 +1,71 @@
+package woocommerce
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithVersion(t *testing.T) {
+	tests := []struct {
+		opts    []Option
+		prefix  string
+		version string
+	}{
+		{nil, "/wp-json/wc/v3", "v3"},
+		{[]Option{WithVersion("v2")}, "/wp-json/wc/v2", "v2"},
+		{[]Option{WithVersion("wc/v1")}, "/wp-json/wc/v1", "v1"},
+		{[]Option{WithVersion("v12")}, "/wp-json/wc/v3", "v3"},
+		{[]Option{WithVersion("latest")}, "/wp-json/wc/v3", "v3"},
+		{[]Option{WithNamespace("/wc-bookings/v1/")}, "/wp-json/wc-bookings/v1", "v1"},
+	}
+	for _, tt := range tests {
+		c := NewClient(App{}, "shop.example.com", tt.opts...)
+		if c.pathPrefix != tt.prefix || c.Version() != tt.version {
+			t.Errorf("prefix, version = %q, %q, want %q, %q", c.pathPrefix, c.Version(), tt.prefix, tt.version)
+		}
+	}
+}
+
+func TestWithVersion_Warning(t *testing.T) {
+	var stderr bytes.Buffer
+	logger := &LeveledLogger{Level: LevelWarn, stderrOverride: &stderr}
+	c := NewClient(App{}, "shop.example.com", WithVersion("wc/v4"), WithLog(logger))
+	if c.Version() != "v3" || !strings.Contains(stderr.String(), `unsupported API version "wc/v4"`) {
+		t.Errorf("version %q, logged %q", c.Version(), stderr.String())
+	}
+
+	stderr.Reset()
+	NewClient(App{}, "shop.example.com", WithVersion("v2"), WithLog(logger))
+	if stderr.Len() != 0 {
+		t.Errorf("logged %q for a supported version", stderr.String())
+	}
+}
+
+func TestWithVersion_Requests(t *testing.T) {
+	var paths []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		// the v1 API embeds variations in products
+		w.Write([]byte(`{"id":7,"type":"variable","variations":[{"id":71,"sku":"MUG-RED"},{"id":72,"sku":"MUG-BLUE"}]}`))
+	})
+	c := newTestClient(t, handler, WithVersion("wc/v1"))
+
+	product, err := c.Product.Get(7, nil)
+	if err != nil || len(product.Variations) != 2 || product.Variations[1] != 72 {
+		t.Fatalf("Get() = %+v, %v", product, err)
+	}
+	if err := c.Get("/wp-json/wc-bookings/v1/bookings/3", nil, nil); err != nil {
+		t.Fatalf("Get() of another namespace err = %v", err)
+	}
+	if paths[0] != "/wp-json/wc/v1/products/7" || paths[1] != "/wp-json/wc-bookings/v1/bookings/3" {
+		t.Errorf("requested paths %v", paths)
+	}
+
+	var ids ProductVariationIDs
+	if err := json.Unmarshal([]byte(`[81,82]`), &ids); err != nil || len(ids) != 2 || ids[0] != 81 {
+		t.Errorf("Unmarshal() of v2+ IDs = %v, %v", ids, err)
+	}
+}
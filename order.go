@@ -9,6 +9,35 @@ const (
 	ordersBasePath = "orders"
 )
 
+// OrderStatus is the status of an order. Plugins can register custom statuses,
+// so values outside the built-in set decode untouched and Valid reports false
+// for them.
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#order-properties
+type OrderStatus string
+
+const (
+	OrderStatusPending       OrderStatus = "pending"
+	OrderStatusProcessing    OrderStatus = "processing"
+	OrderStatusOnHold        OrderStatus = "on-hold"
+	OrderStatusCompleted     OrderStatus = "completed"
+	OrderStatusCancelled     OrderStatus = "cancelled"
+	OrderStatusRefunded      OrderStatus = "refunded"
+	OrderStatusFailed        OrderStatus = "failed"
+	OrderStatusTrash         OrderStatus = "trash"
+	OrderStatusCheckoutDraft OrderStatus = "checkout-draft"
+)
+
+// Valid reports whether s is one of WooCommerce's built-in order statuses
+func (s OrderStatus) Valid() bool {
+	switch s {
+	case OrderStatusPending, OrderStatusProcessing, OrderStatusOnHold, OrderStatusCompleted,
+		OrderStatusCancelled, OrderStatusRefunded, OrderStatusFailed, OrderStatusTrash,
+		OrderStatusCheckoutDraft:
+		return true
+	}
+	return false
+}
+
 // OrderService is an interface for interfacing with the orders endpoints of woocommerce API
 // https://woocommerce.github.io/woocommerce-rest-api-docs/#orders
 type OrderService interface {
@@ -80,7 +109,7 @@ type Order struct {
 	OrderKey           string          `json:"order_key,omitempty"`
 	CreatedVia         string          `json:"created_via,omitempty"`
 	Version            string          `json:"version,omitempty"`
-	Status             string          `json:"status,omitempty"`
+	Status             OrderStatus     `json:"status,omitempty"`
 	Currency           string          `json:"currency,omitempty"`
 	DateCreated        string          `json:"date_created,omitempty"`
 	DateCreatedGmt     string          `json:"date_created_gmt,omitempty"`
@@ -117,6 +146,7 @@ type Order struct {
 	Refunds            []Refund        `json:"refunds,omitempty"`
 	CurrencySymbol     string          `json:"currency_symbol,omitempty"`
 	Links              Links           `json:"_links"`
+	Embedded           Embedded        `json:"_embedded,omitempty"`
 	SetPaid            bool            `json:"set_paid,omitempty"`
 }
 
@@ -156,26 +186,36 @@ type Shipping struct {
 }
 
 type LineItem struct {
-	ID          int64      `json:"id,omitempty"`
-	Name        string     `json:"name,omitempty"`
-	ProductID   int64      `json:"product_id,omitempty"`
-	VariantID   int64      `json:"variant_id,omitempty"`
-	Quantity    int        `json:"quantity,omitempty"`
-	TaxClass    string     `json:"tax_class,omitempty"`
-	SubTotal    string     `json:"subtotal,omitempty"`
-	SubtotalTax string     `json:"subtotal_tax,omitempty"`
-	Total       string     `json:"total,omitempty"`
-	TotalTax    string     `json:"total_tax,omitempty"`
-	Taxes       []TaxLine  `json:"taxes,omitempty"`
-	MetaData    []MetaData `json:"meta_data,omitempty"`
-	SKU         string     `json:"sku,omitempty"`
-	Price       float64    `json:"price,omitempty"`
+	ID        int64  `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	ProductID int64  `json:"product_id,omitempty"`
+	// Deprecated: WooCommerce ignores variant_id, use VariationID.
+	VariantID   int64         `json:"variant_id,omitempty"`
+	VariationID int64         `json:"variation_id,omitempty"`
+	Quantity    int           `json:"quantity,omitempty"`
+	TaxClass    string        `json:"tax_class,omitempty"`
+	SubTotal    string        `json:"subtotal,omitempty"`
+	SubtotalTax string        `json:"subtotal_tax,omitempty"`
+	Total       string        `json:"total,omitempty"`
+	TotalTax    string        `json:"total_tax,omitempty"`
+	Taxes       []LineItemTax `json:"taxes,omitempty"`
+	MetaData    []MetaData    `json:"meta_data,omitempty"`
+	SKU         string        `json:"sku,omitempty"`
+	Price       float64       `json:"price,omitempty"`
+}
+
+// LineItemTax is the share of a tax rate charged on a line, fee or shipping item.
+// ID is the tax rate ID, matching TaxLine.RateId.
+type LineItemTax struct {
+	ID       int64  `json:"id,omitempty"`
+	Total    string `json:"total,omitempty"`
+	Subtotal string `json:"subtotal,omitempty"`
 }
 
 type TaxLine struct {
 	ID               int64      `json:"id,omitempty"`
 	RateCode         string     `json:"rate_code,omitempty"`
-	RateId           string     `json:"rate_id,omitempty"`
+	RateId           int64      `json:"rate_id,omitempty"`
 	Label            string     `json:"label,omitempty"`
 	Compound         bool       `json:"compound,omitempty"`
 	TaxTotal         string     `json:"tax_total"`
@@ -190,14 +230,14 @@ type MetaData struct {
 }
 
 type FeeLine struct {
-	ID        int64      `json:"id,omitempty"`
-	Name      string     `json:"name,omitempty"`
-	TaxClass  string     `json:"tax_class,omitempty"`
-	TaxStatus string     `json:"tax_status,omitempty"`
-	Total     string     `json:"total,omitempty"`
-	TotalTax  string     `json:"total_tax,omitempty"`
-	Taxes     []TaxLine  `json:"taxes,omitempty"`
-	MetaData  []MetaData `json:"meta_data,omitempty"`
+	ID        int64         `json:"id,omitempty"`
+	Name      string        `json:"name,omitempty"`
+	TaxClass  string        `json:"tax_class,omitempty"`
+	TaxStatus string        `json:"tax_status,omitempty"`
+	Total     string        `json:"total,omitempty"`
+	TotalTax  string        `json:"total_tax,omitempty"`
+	Taxes     []LineItemTax `json:"taxes,omitempty"`
+	MetaData  []MetaData    `json:"meta_data,omitempty"`
 }
 
 type Refund struct {
@@ -207,13 +247,13 @@ type Refund struct {
 }
 
 type ShippingLines struct {
-	ID          int64      `json:"id,omitempty"`
-	MethodTitle string     `json:"method_title,omitempty"`
-	MethodID    string     `json:"method_id,omitempty"`
-	Total       string     `json:"total,omitempty"`
-	TotalTax    string     `json:"total_tax,omitempty"`
-	Taxes       []TaxLine  `json:"tax_lines,omitempty"`
-	MetaData    []MetaData `json:"meta_data,omitempty"`
+	ID          int64         `json:"id,omitempty"`
+	MethodTitle string        `json:"method_title,omitempty"`
+	MethodID    string        `json:"method_id,omitempty"`
+	Total       string        `json:"total,omitempty"`
+	TotalTax    string        `json:"total_tax,omitempty"`
+	Taxes       []LineItemTax `json:"taxes,omitempty"`
+	MetaData    []MetaData    `json:"meta_data,omitempty"`
 }
 
 type CouponLine struct {
@@ -1,6 +1,7 @@
 package woocommerce
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -155,3 +156,24 @@ func TestOrderServiceOp_Batch(t *testing.T) {
 		t.Logf(" order id: %v, order status : %v", order.ID, order.Status)
 	}
 }
+
+func TestOrderStatus_Valid(t *testing.T) {
+	for _, status := range []OrderStatus{OrderStatusPending, OrderStatusProcessing, OrderStatusOnHold, OrderStatusCompleted} {
+		if !status.Valid() {
+			t.Errorf("%s should be valid", status)
+		}
+	}
+	if OrderStatus("procesing").Valid() {
+		t.Errorf("misspelt status should not be valid")
+	}
+}
+
+func TestOrderStatus_UnmarshalCustom(t *testing.T) {
+	var order Order
+	if err := json.Unmarshal([]byte(`{"id":1,"status":"awaiting-shipment"}`), &order); err != nil {
+		t.Fatalf("decode order with custom status: %v", err)
+	}
+	if order.Status != "awaiting-shipment" || order.Status.Valid() {
+		t.Errorf("custom status = %q, valid = %v", order.Status, order.Status.Valid())
+	}
+}
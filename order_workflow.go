@@ -0,0 +1,152 @@
+package woocommerce
+
+import (
+	"fmt"
+	"sync"
+)
+
+// defaultOrderTransitions is WooCommerce's order status graph as admins and
+// gateways drive it. WooCommerce itself accepts any status over the API, the
+// graph encodes which moves make business sense.
+var defaultOrderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusCheckoutDraft: {OrderStatusPending, OrderStatusProcessing, OrderStatusOnHold, OrderStatusFailed, OrderStatusCancelled},
+	OrderStatusPending:       {OrderStatusProcessing, OrderStatusOnHold, OrderStatusCompleted, OrderStatusCancelled, OrderStatusFailed},
+	OrderStatusProcessing:    {OrderStatusCompleted, OrderStatusOnHold, OrderStatusCancelled, OrderStatusRefunded, OrderStatusFailed},
+	OrderStatusOnHold:        {OrderStatusPending, OrderStatusProcessing, OrderStatusCompleted, OrderStatusCancelled, OrderStatusFailed},
+	OrderStatusFailed:        {OrderStatusPending, OrderStatusProcessing, OrderStatusOnHold, OrderStatusCancelled},
+	OrderStatusCompleted:     {OrderStatusRefunded},
+	OrderStatusCancelled:     {OrderStatusPending, OrderStatusProcessing},
+	OrderStatusRefunded:      {},
+}
+
+// OrderTransitionError is returned when an order cannot move from one status to another
+type OrderTransitionError struct {
+	OrderID int64
+	From    OrderStatus
+	To      OrderStatus
+}
+
+func (e OrderTransitionError) Error() string {
+	return fmt.Sprintf("order %d: transition from %q to %q is not allowed", e.OrderID, e.From, e.To)
+}
+
+// OrderTransition describes the side effects applied together with a status change
+type OrderTransition struct {
+	// Note, when not empty, is added to the order as an OrderNote after the update.
+	Note string
+	// CustomerNote makes Note visible to, and emailed to, the customer.
+	CustomerNote bool
+	// SetPaid marks the order paid, which also reduces stock in WooCommerce.
+	SetPaid bool
+	// TransactionId is the gateway transaction reference, recorded when the order is marked paid.
+	TransactionId string
+}
+
+// OrderWorkflow applies guarded status transitions to orders through OrderService
+// and OrderNoteService.
+type OrderWorkflow struct {
+	orders OrderService
+	notes  OrderNoteService
+
+	mu          sync.RWMutex
+	transitions map[OrderStatus]map[OrderStatus]bool
+}
+
+// NewOrderWorkflow returns an OrderWorkflow using the client's order services and
+// WooCommerce's built-in status graph.
+func NewOrderWorkflow(c *Client) *OrderWorkflow {
+	w := &OrderWorkflow{
+		orders:      c.Order,
+		notes:       c.OrderNote,
+		transitions: make(map[OrderStatus]map[OrderStatus]bool),
+	}
+	for from, targets := range defaultOrderTransitions {
+		w.allow(from, targets...)
+	}
+	return w
+}
+
+// RegisterStatus adds a custom order status (as registered by a plugin) to the graph,
+// reachable from each of from and able to move to each of to.
+func (w *OrderWorkflow) RegisterStatus(status OrderStatus, from []OrderStatus, to []OrderStatus) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, source := range from {
+		w.allow(source, status)
+	}
+	w.allow(status, to...)
+}
+
+// allow records the edges from -> targets, callers hold mu when needed.
+func (w *OrderWorkflow) allow(from OrderStatus, targets ...OrderStatus) {
+	if w.transitions[from] == nil {
+		w.transitions[from] = make(map[OrderStatus]bool)
+	}
+	for _, to := range targets {
+		w.transitions[from][to] = true
+	}
+}
+
+// CanTransition reports whether an order may move from one status to another.
+// Staying in the same status is always allowed.
+func (w *OrderWorkflow) CanTransition(from, to OrderStatus) bool {
+	if from == to {
+		return true
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.transitions[from][to]
+}
+
+// Transitions returns the statuses an order in status from may move to
+func (w *OrderWorkflow) Transitions(from OrderStatus) []OrderStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	targets := make([]OrderStatus, 0, len(w.transitions[from]))
+	for to := range w.transitions[from] {
+		targets = append(targets, to)
+	}
+	return targets
+}
+
+// Transition fetches the order, validates the move to status to, and applies it with
+// OrderService.Update. An OrderTransitionError is returned when the move is not allowed.
+func (w *OrderWorkflow) Transition(orderID int64, to OrderStatus, transition OrderTransition) (*Order, error) {
+	order, err := w.orders.Get(orderID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !w.CanTransition(order.Status, to) {
+		return nil, OrderTransitionError{OrderID: orderID, From: order.Status, To: to}
+	}
+
+	update := &Order{
+		ID:     orderID,
+		Status: to,
+	}
+	if transition.SetPaid {
+		update.SetPaid = true
+		update.TransactionId = transition.TransactionId
+	}
+	updated, err := w.orders.Update(update)
+	if err != nil {
+		return nil, err
+	}
+
+	if transition.Note != "" {
+		note := OrderNote{Note: transition.Note, CustomerNote: transition.CustomerNote}
+		if _, err := w.notes.CreateNote(orderID, note); err != nil {
+			return updated, err
+		}
+	}
+	return updated, nil
+}
+
+// MarkPaid moves the order to processing and records the payment
+func (w *OrderWorkflow) MarkPaid(orderID int64, transactionId, note string) (*Order, error) {
+	return w.Transition(orderID, OrderStatusProcessing, OrderTransition{
+		Note:          note,
+		SetPaid:       true,
+		TransactionId: transactionId,
+	})
+}
@@ -0,0 +1,70 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestOrderWorkflow_CanTransition(t *testing.T) {
+	w := NewOrderWorkflow(client)
+	tests := []struct {
+		from, to OrderStatus
+		want     bool
+	}{
+		{OrderStatusPending, OrderStatusProcessing, true},
+		{OrderStatusProcessing, OrderStatusCompleted, true},
+		{OrderStatusCompleted, OrderStatusRefunded, true},
+		{OrderStatusCompleted, OrderStatusPending, false},
+		{OrderStatusRefunded, OrderStatusProcessing, false},
+		{OrderStatusOnHold, OrderStatusOnHold, true},
+		{"awaiting-shipment", OrderStatusCompleted, false},
+	}
+	for _, tt := range tests {
+		if got := w.CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	w.RegisterStatus("awaiting-shipment", []OrderStatus{OrderStatusProcessing}, []OrderStatus{OrderStatusCompleted})
+	if !w.CanTransition(OrderStatusProcessing, "awaiting-shipment") || !w.CanTransition("awaiting-shipment", OrderStatusCompleted) {
+		t.Errorf("registered custom status transitions are not allowed")
+	}
+}
+
+func TestOrderWorkflow_Transition(t *testing.T) {
+	var updated Order
+	var note OrderNote
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/orders/7", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			json.NewDecoder(r.Body).Decode(&updated)
+			json.NewEncoder(w).Encode(updated)
+			return
+		}
+		json.NewEncoder(w).Encode(Order{ID: 7, Status: OrderStatusPending})
+	})
+	mux.HandleFunc("/wp-json/wc/v3/orders/7/notes", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&note)
+		json.NewEncoder(w).Encode(note)
+	})
+	w := NewOrderWorkflow(newTestClient(t, mux))
+
+	order, err := w.MarkPaid(7, "txn_123", "Paid via gateway")
+	if err != nil {
+		t.Fatalf("MarkPaid: %v", err)
+	}
+	if order.Status != OrderStatusProcessing || !updated.SetPaid || updated.TransactionId != "txn_123" {
+		t.Errorf("unexpected update sent: %+v", updated)
+	}
+	if note.Note != "Paid via gateway" {
+		t.Errorf("note = %q", note.Note)
+	}
+
+	_, err = w.Transition(7, OrderStatusRefunded, OrderTransition{})
+	var transitionErr OrderTransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.From != OrderStatusPending {
+		t.Errorf("Transition(pending -> refunded) err = %v", err)
+	}
+}
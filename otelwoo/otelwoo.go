@@ -0,0 +1,174 @@
+// Package otelwoo traces the calls of a woocommerce.Client with OpenTelemetry.
+//
+// Every API call gets a span named after its resource and operation, e.g.
+// "woocommerce.orders.update", recording how many times it was retried. Each of
+// its attempts gets a child client span, "woocommerce.orders.update.attempt",
+// whose W3C trace context is sent with the request. Call spans are children of
+// the span found in the context given to the client's ctx-aware methods, such as
+// Client.GetWithContext.
+//
+//	client := woocommerce.NewClient(app, "shop.example.com", otelwoo.Tracing())
+package otelwoo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/chenyangguang/woocommerce"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/chenyangguang/woocommerce/otelwoo"
+
+// Span attributes set besides the HTTP ones
+const (
+	AttributeResource      = attribute.Key("woocommerce.resource")
+	AttributeOperation     = attribute.Key("woocommerce.operation")
+	AttributeEndpoint      = attribute.Key("woocommerce.endpoint")
+	AttributeAttempt       = attribute.Key("woocommerce.attempt")
+	AttributeRetries       = attribute.Key("woocommerce.retries")
+	AttributeRateLimitWait = attribute.Key("woocommerce.rate_limit.wait_seconds")
+	AttributeErrorCode     = attribute.Key("woocommerce.error_code")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// Option configures Tracing, RequestMiddleware and Middleware
+type Option func(c *config)
+
+// WithTracerProvider sets the provider spans are created with, the global one by default
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithPropagator sets how the trace context is sent, W3C trace context by default
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = propagator
+	}
+}
+
+// SpanName returns the name of the span of a call, e.g.
+// "woocommerce.products.variations.list"
+func SpanName(call *woocommerce.Call) string {
+	return fmt.Sprintf("woocommerce.%s.%s", call.Resource(), call.Operation())
+}
+
+// AttemptSpanName returns the name of the span of a call attempt, e.g.
+// "woocommerce.products.variations.list.attempt"
+func AttemptSpanName(call *woocommerce.Call) string {
+	return SpanName(call) + ".attempt"
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		propagator:     propagation.TraceContext{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// Tracing returns a woocommerce.Option tracing every call and its attempts, it
+// registers RequestMiddleware and Middleware
+func Tracing(opts ...Option) woocommerce.Option {
+	return func(c *woocommerce.Client) {
+		woocommerce.WithRequestMiddleware(RequestMiddleware(opts...))(c)
+		woocommerce.WithMiddleware(Middleware(opts...))(c)
+	}
+}
+
+// RequestMiddleware returns a woocommerce.RequestMiddleware tracing every call,
+// its retries included
+func RequestMiddleware(opts ...Option) woocommerce.RequestMiddleware {
+	tracer := newConfig(opts).tracerProvider.Tracer(instrumentationName)
+
+	return func(next woocommerce.RequestHandler) woocommerce.RequestHandler {
+		return func(call *woocommerce.Call) error {
+			ctx, span := tracer.Start(call.Request.Context(), SpanName(call),
+				trace.WithAttributes(
+					attribute.String("http.request.method", call.Method),
+					AttributeResource.String(call.Resource()),
+					AttributeOperation.String(call.Operation()),
+					AttributeEndpoint.String(call.Endpoint()),
+				))
+			defer span.End()
+
+			call.Request = call.Request.WithContext(ctx)
+			err := next(call)
+			retries := call.Attempt - 1
+			if retries < 0 {
+				retries = 0 // answered without an attempt
+			}
+			span.SetAttributes(AttributeRetries.Int(retries))
+			if call.RateLimitWait > 0 {
+				span.SetAttributes(AttributeRateLimitWait.Float64(call.RateLimitWait.Seconds()))
+			}
+			if err != nil {
+				var responseErr woocommerce.ResponseError
+				if errors.As(err, &responseErr) {
+					span.SetAttributes(attribute.Int("http.response.status_code", responseErr.Status))
+				}
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
+
+// Middleware returns a woocommerce.Middleware tracing every call attempt
+func Middleware(opts ...Option) woocommerce.Middleware {
+	cfg := newConfig(opts)
+	tracer := cfg.tracerProvider.Tracer(instrumentationName)
+
+	return func(next woocommerce.CallHandler) woocommerce.CallHandler {
+		return func(call *woocommerce.Call) (*http.Response, error) {
+			ctx, span := tracer.Start(call.Request.Context(), AttemptSpanName(call),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("http.request.method", call.Method),
+					attribute.String("server.address", call.Request.URL.Hostname()),
+					AttributeResource.String(call.Resource()),
+					AttributeOperation.String(call.Operation()),
+					AttributeEndpoint.String(call.Endpoint()),
+					AttributeAttempt.Int(call.Attempt),
+				))
+			defer span.End()
+			if call.RateLimitWait > 0 {
+				span.SetAttributes(AttributeRateLimitWait.Float64(call.RateLimitWait.Seconds()))
+			}
+
+			call.Request = call.Request.WithContext(ctx)
+			cfg.propagator.Inject(ctx, propagation.HeaderCarrier(call.Request.Header))
+
+			resp, err := next(call)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return resp, err
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+			if resp.StatusCode >= http.StatusBadRequest {
+				body, _ := woocommerce.ReadResponseBody(resp)
+				if code := woocommerce.ResponseErrorCode(body); code != "" {
+					span.SetAttributes(AttributeErrorCode.String(code))
+				}
+				span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+			}
+			return resp, nil
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package otelwoo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chenyangguang/woocommerce"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	var traceparents []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		if strings.HasSuffix(r.URL.Path, "/orders/404") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"woocommerce_rest_shop_order_invalid_id","message":"Invalid ID."}`))
+			return
+		}
+		w.Write([]byte(`{"id":12}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := woocommerce.NewClient(woocommerce.App{CustomerKey: "ck", CustomerSecret: "cs"},
+		strings.TrimPrefix(server.URL, "https://"), Tracing(WithTracerProvider(provider)))
+	client.Client = server.Client()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "sync orders")
+	var order woocommerce.Order
+	if err := client.PutWithContext(ctx, "orders/12", map[string]string{"status": "completed"}, &order); err != nil {
+		t.Fatalf("PutWithContext() err = %v", err)
+	}
+	if err := client.GetWithContext(ctx, "orders/404", &order, nil); err == nil {
+		t.Fatal("GetWithContext() of a missing order succeeded")
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 5 {
+		t.Fatalf("recorded %d spans", len(spans))
+	}
+	update, updateCall, get, getCall := spans[0], spans[1], spans[2], spans[3]
+	if update.Name() != "woocommerce.orders.update.attempt" || get.Name() != "woocommerce.orders.get.attempt" {
+		t.Errorf("attempt span names = %q, %q", update.Name(), get.Name())
+	}
+	if updateCall.Name() != "woocommerce.orders.update" || getCall.Name() != "woocommerce.orders.get" {
+		t.Errorf("call span names = %q, %q", updateCall.Name(), getCall.Name())
+	}
+	if updateCall.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("call span is not a child of the context's span")
+	}
+	if update.Parent().SpanID() != updateCall.SpanContext().SpanID() {
+		t.Error("attempt span is not a child of its call span")
+	}
+	if !strings.Contains(traceparents[0], update.SpanContext().SpanID().String()) {
+		t.Errorf("traceparent = %q, want the attempt span", traceparents[0])
+	}
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range get.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs[AttributeErrorCode].AsString() != "woocommerce_rest_shop_order_invalid_id" || attrs["http.response.status_code"].AsInt64() != 404 || get.Status().Code != codes.Error {
+		t.Errorf("failed attempt span attributes = %v, status %v", attrs, get.Status())
+	}
+	if getCall.Status().Code != codes.Error {
+		t.Errorf("failed call span status = %v", getCall.Status())
+	}
+}
+
+func TestTracing_Retries(t *testing.T) {
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"code":"unavailable","message":"Try again."}`))
+			return
+		}
+		w.Write([]byte(`{"id":12}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := woocommerce.NewClient(woocommerce.App{CustomerKey: "ck", CustomerSecret: "cs"},
+		strings.TrimPrefix(server.URL, "https://"), woocommerce.WithRetry(3), Tracing(WithTracerProvider(provider)))
+	client.Client = server.Client()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "sync orders")
+	var order woocommerce.Order
+	if err := client.GetWithContext(ctx, "orders/12", &order, nil); err != nil {
+		t.Fatalf("GetWithContext() err = %v", err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("recorded %d spans", len(spans))
+	}
+	call := spans[2]
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range call.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if call.Name() != "woocommerce.orders.get" || attrs[AttributeRetries].AsInt64() != 1 || call.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("call span = %q, retries %v", call.Name(), attrs[AttributeRetries])
+	}
+	for n, span := range spans[:2] {
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, kv := range span.Attributes() {
+			attrs[kv.Key] = kv.Value
+		}
+		if span.Name() != "woocommerce.orders.get.attempt" || attrs[AttributeAttempt].AsInt64() != int64(n+1) || span.Parent().SpanID() != call.SpanContext().SpanID() {
+			t.Errorf("span %d = %q, attempt %v", n, span.Name(), attrs[AttributeAttempt])
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package woocommerce
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"regexp"
@@ -14,6 +15,104 @@ const (
 
 var linkRegex = regexp.MustCompile(`^ *<([^>]+)>; rel="(prev|next|first|last)" *$`)
 
+// ProductType is the type of a product
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#product-properties
+type ProductType string
+
+const (
+	ProductTypeSimple   ProductType = "simple"
+	ProductTypeGrouped  ProductType = "grouped"
+	ProductTypeExternal ProductType = "external"
+	ProductTypeVariable ProductType = "variable"
+	// ProductTypeVariation is the type of the variations the products endpoint
+	// returns when filtering by SKU, they cannot be created as products.
+	ProductTypeVariation ProductType = "variation"
+)
+
+// Valid reports whether t is one of WooCommerce's built-in product types
+func (t ProductType) Valid() bool {
+	switch t {
+	case ProductTypeSimple, ProductTypeGrouped, ProductTypeExternal, ProductTypeVariable:
+		return true
+	}
+	return false
+}
+
+// ProductStatus is the publication status of a product or variation
+type ProductStatus string
+
+const (
+	ProductStatusDraft   ProductStatus = "draft"
+	ProductStatusPending ProductStatus = "pending"
+	ProductStatusPrivate ProductStatus = "private"
+	ProductStatusPublish ProductStatus = "publish"
+	ProductStatusFuture  ProductStatus = "future"
+)
+
+// Valid reports whether s is one of WooCommerce's built-in product statuses
+func (s ProductStatus) Valid() bool {
+	switch s {
+	case ProductStatusDraft, ProductStatusPending, ProductStatusPrivate, ProductStatusPublish, ProductStatusFuture:
+		return true
+	}
+	return false
+}
+
+// StockStatus is the stock status of a product or variation
+type StockStatus string
+
+const (
+	StockStatusInStock     StockStatus = "instock"
+	StockStatusOutOfStock  StockStatus = "outofstock"
+	StockStatusOnBackorder StockStatus = "onbackorder"
+)
+
+// Valid reports whether s is one of WooCommerce's built-in stock statuses
+func (s StockStatus) Valid() bool {
+	switch s {
+	case StockStatusInStock, StockStatusOutOfStock, StockStatusOnBackorder:
+		return true
+	}
+	return false
+}
+
+// CatalogVisibility controls where a product is shown in the shop
+type CatalogVisibility string
+
+const (
+	CatalogVisibilityVisible CatalogVisibility = "visible"
+	CatalogVisibilityCatalog CatalogVisibility = "catalog"
+	CatalogVisibilitySearch  CatalogVisibility = "search"
+	CatalogVisibilityHidden  CatalogVisibility = "hidden"
+)
+
+// Valid reports whether v is one of WooCommerce's built-in catalog visibilities
+func (v CatalogVisibility) Valid() bool {
+	switch v {
+	case CatalogVisibilityVisible, CatalogVisibilityCatalog, CatalogVisibilitySearch, CatalogVisibilityHidden:
+		return true
+	}
+	return false
+}
+
+// BackorderPolicy tells whether a product or variation accepts backorders
+type BackorderPolicy string
+
+const (
+	BackorderPolicyNo     BackorderPolicy = "no"
+	BackorderPolicyNotify BackorderPolicy = "notify"
+	BackorderPolicyYes    BackorderPolicy = "yes"
+)
+
+// Valid reports whether b is one of WooCommerce's built-in backorder policies
+func (b BackorderPolicy) Valid() bool {
+	switch b {
+	case BackorderPolicyNo, BackorderPolicyNotify, BackorderPolicyYes:
+		return true
+	}
+	return false
+}
+
 // ProductService allows you to create, view, update, and delete individual, or a batch, of products
 // https://woocommerce.github.io/woocommerce-rest-api-docs/#products
 type ProductService interface {
@@ -23,88 +122,108 @@ type ProductService interface {
 	Update(product *Product) (*Product, error)
 	Delete(productID int64, options interface{}) (*Product, error)
 	Batch(data ProductBatchOption) (*ProductBatchResource, error)
+	GetBySKU(sku string) (*Product, error)
+	ListBySKUs(skus []string) ([]Product, error)
 }
 
 // Product represent WooCommerce Product
 // https://woocommerce.github.io/woocommerce-rest-api-docs/#product-properties
 type Product struct {
-	ID                int64                  `json:"id,omitempty"`
-	Name              string                 `json:"name,omitempty"`
-	Slug              string                 `json:"slug,omitempty"`
-	Permalink         string                 `json:"permalink,omitempty"`
-	DateCreated       string                 `json:"date_created,omitempty"`
-	DateCreatedGmt    string                 `json:"date_created_gmt,omitempty"`
-	DateModified      string                 `json:"date_modified,omitempty"`
-	DateModifiedGmt   string                 `json:"date_modified_gmt,omitempty"`
-	Type              string                 `json:"type,omitempty"`
-	Status            string                 `json:"status,omitempty"`
-	Featured          bool                   `json:"featured,omitempty"`
-	CatalogVisibility string                 `json:"catalog_visibility,omitempty"`
-	Description       string                 `json:"description,omitempty"`
-	ShortDescription  string                 `json:"short_description,omitempty"`
-	SKU               string                 `json:"sku,omitempty"`
-	Price             string                 `json:"price,omitempty"`
-	RegularPrice      string                 `json:"regular_price,omitempty"`
-	SalePrice         string                 `json:"sale_price,omitempty"`
-	DateOnSaleFrom    string                 `json:"date_on_sale_from,omitempty"`
-	DateOnSaleTo      string                 `json:"date_on_sale_to,omitempty"`
-	TotalSales        int64                  `json:"total_sales,omitempty"`
-	TaxStatus         string                 `json:"tax_status,omitempty"`
-	TaxClass          string                 `json:"tax_class,omitempty"`
-	ManageStock       bool                   `json:"manage_stock,omitempty"`
-	StockQuantity     string                 `json:"stock_quantity,omitempty"`
-	StockStatus       string                 `json:"stock_status,omitempty"`
-	Backorders        string                 `json:"backorders,omitempty"`
-	LowStockAmount    string                 `json:"low_stock_amount,omitempty"`
-	SoldIndividually  bool                   `json:"sold_individually,omitempty"`
-	Weight            string                 `json:"weight,omitempty"`
-	Length            string                 `json:"length,omitempty"`
-	Width             string                 `json:"width,omitempty"`
-	Height            string                 `json:"height,omitempty"`
-	Dimensions        map[string]string      `json:"dimensions,omitempty"`
-	ShippingClass     string                 `json:"shipping_class,omitempty"`
-	ShippingRequired  bool                   `json:"shipping_required,omitempty"`
-	ShippingTaxable   bool                   `json:"shipping_taxable,omitempty"`
-	ShippingClassID   int64                  `json:"shipping_class_id,omitempty"`
-	ReviewsAllowed    string                 `json:"reviews_allowed,omitempty"`
-	AverageRating     string                 `json:"average_rating,omitempty"`
-	RatingCount       int64                  `json:"rating_count,omitempty"`
-	RelatedIDs        []int64                `json:"related_ids,omitempty"`
-	UpsellIDs         []int64                `json:"upsell_ids,omitempty"`
-	CrossSellIDs      []int64                `json:"cross_sell_ids,omitempty"`
-	ParentID          int64                  `json:"parent_id,omitempty"`
-	PurchaseNote      string                 `json:"purchase_note,omitempty"`
-	MenuOrder         int                    `json:"menu_order,omitempty"`
-	Attributes        []ProductAttribute     `json:"attributes,omitempty"`
-	DefaultAttributes map[string]interface{} `json:"default_attributes,omitempty"`
-	Categories        []int64                `json:"categories,omitempty"`
-	Tags              []int64                `json:"tags,omitempty"`
-	Images            []ProductImage         `json:"images,omitempty"`
-	Downloads         []ProductDownload      `json:"downloads,omitempty"`
-	MetaData          []MetaData             `json:"meta_data,omitempty"`
-	Links             Links                  `json:"_links,omitempty"`
+	ID                int64                     `json:"id,omitempty"`
+	Name              string                    `json:"name,omitempty"`
+	Slug              string                    `json:"slug,omitempty"`
+	Permalink         string                    `json:"permalink,omitempty"`
+	DateCreated       string                    `json:"date_created,omitempty"`
+	DateCreatedGmt    string                    `json:"date_created_gmt,omitempty"`
+	DateModified      string                    `json:"date_modified,omitempty"`
+	DateModifiedGmt   string                    `json:"date_modified_gmt,omitempty"`
+	Type              ProductType               `json:"type,omitempty"`
+	Status            ProductStatus             `json:"status,omitempty"`
+	Featured          bool                      `json:"featured,omitempty"`
+	CatalogVisibility CatalogVisibility         `json:"catalog_visibility,omitempty"`
+	Description       string                    `json:"description,omitempty"`
+	ShortDescription  string                    `json:"short_description,omitempty"`
+	SKU               string                    `json:"sku,omitempty"`
+	Price             string                    `json:"price,omitempty"`
+	RegularPrice      string                    `json:"regular_price,omitempty"`
+	SalePrice         string                    `json:"sale_price,omitempty"`
+	DateOnSaleFrom    string                    `json:"date_on_sale_from,omitempty"`
+	DateOnSaleFromGmt string                    `json:"date_on_sale_from_gmt,omitempty"`
+	DateOnSaleTo      string                    `json:"date_on_sale_to,omitempty"`
+	DateOnSaleToGmt   string                    `json:"date_on_sale_to_gmt,omitempty"`
+	PriceHtml         string                    `json:"price_html,omitempty"`
+	OnSale            bool                      `json:"on_sale,omitempty"`
+	Purchasable       bool                      `json:"purchasable,omitempty"`
+	TotalSales        int64                     `json:"total_sales,omitempty"`
+	Virtual           bool                      `json:"virtual,omitempty"`
+	Downloadable      bool                      `json:"downloadable,omitempty"`
+	Downloads         []ProductDownload         `json:"downloads,omitempty"`
+	DownloadLimit     int64                     `json:"download_limit,omitempty"`
+	DownloadExpiry    int64                     `json:"download_expiry,omitempty"`
+	ExternalUrl       string                    `json:"external_url,omitempty"`
+	ButtonText        string                    `json:"button_text,omitempty"`
+	TaxStatus         string                    `json:"tax_status,omitempty"`
+	TaxClass          string                    `json:"tax_class,omitempty"`
+	ManageStock       bool                      `json:"manage_stock,omitempty"`
+	StockQuantity     *int64                    `json:"stock_quantity,omitempty"`
+	StockStatus       StockStatus               `json:"stock_status,omitempty"`
+	Backorders        BackorderPolicy           `json:"backorders,omitempty"`
+	BackordersAllowed bool                      `json:"backorders_allowed,omitempty"`
+	Backordered       bool                      `json:"backordered,omitempty"`
+	LowStockAmount    *int64                    `json:"low_stock_amount,omitempty"`
+	SoldIndividually  bool                      `json:"sold_individually,omitempty"`
+	Weight            string                    `json:"weight,omitempty"`
+	Length            string                    `json:"length,omitempty"`
+	Width             string                    `json:"width,omitempty"`
+	Height            string                    `json:"height,omitempty"`
+	Dimensions        map[string]string         `json:"dimensions,omitempty"`
+	ShippingClass     string                    `json:"shipping_class,omitempty"`
+	ShippingRequired  bool                      `json:"shipping_required,omitempty"`
+	ShippingTaxable   bool                      `json:"shipping_taxable,omitempty"`
+	ShippingClassID   int64                     `json:"shipping_class_id,omitempty"`
+	ReviewsAllowed    bool                      `json:"reviews_allowed,omitempty"`
+	AverageRating     string                    `json:"average_rating,omitempty"`
+	RatingCount       int64                     `json:"rating_count,omitempty"`
+	RelatedIDs        []int64                   `json:"related_ids,omitempty"`
+	UpsellIDs         []int64                   `json:"upsell_ids,omitempty"`
+	CrossSellIDs      []int64                   `json:"cross_sell_ids,omitempty"`
+	ParentID          int64                     `json:"parent_id,omitempty"`
+	PurchaseNote      string                    `json:"purchase_note,omitempty"`
+	Categories        []ProductCategoryRef      `json:"categories,omitempty"`
+	Tags              []ProductTagRef           `json:"tags,omitempty"`
+	Images            []ProductImage            `json:"images,omitempty"`
+	Attributes        []ProductAttribute        `json:"attributes,omitempty"`
+	DefaultAttributes []ProductDefaultAttribute `json:"default_attributes,omitempty"`
+	Variations        ProductVariationIDs       `json:"variations,omitempty"`
+	GroupedProducts   []int64                   `json:"grouped_products,omitempty"`
+	MenuOrder         int                       `json:"menu_order,omitempty"`
+	MetaData          []MetaData                `json:"meta_data,omitempty"`
+	Links             Links                     `json:"_links,omitempty"`
+	Embedded          Embedded                  `json:"_embedded,omitempty"`
+	// Error is set on the items of a batch response WooCommerce rejected.
+	Error *BatchItemError `json:"error,omitempty"`
 }
 
 // ProductListOption list all the product list option request params
 type ProductListOption struct {
 	ListOptions
-	Search        string  `url:"search,omitempty"`
-	After         string  `url:"after,omitempty"`
-	Before        string  `url:"before,omitempty"`
-	Exclude       []int64 `url:"exclude,omitempty"`
-	Include       []int64 `url:"include,omitempty"`
-	Offset        int     `url:"offset,omitempty"`
-	Order         string  `url:"order,omitempty"`
-	Orderby       string  `url:"orderby,omitempty"`
-	Type          string  `url:"type,omitempty"`
-	SKU           string  `url:"sku,omitempty"`
-	Featured      bool    `url:"featured,omitempty"`
-	Category      []int64 `url:"category,omitempty"`
-	Tag           []int64 `url:"tag,omitempty"`
-	ShippingClass string  `url:"shipping_class,omitempty"`
-	Attribute     string  `url:"attribute,omitempty"`
-	AttributeTerm string  `url:"attribute_term,omitempty"`
-	StockStatus   string  `url:"stock_status,omitempty"`
+	Search        string      `url:"search,omitempty"`
+	After         string      `url:"after,omitempty"`
+	Before        string      `url:"before,omitempty"`
+	Exclude       []int64     `url:"exclude,omitempty"`
+	Include       []int64     `url:"include,omitempty"`
+	Offset        int         `url:"offset,omitempty"`
+	Order         string      `url:"order,omitempty"`
+	Orderby       string      `url:"orderby,omitempty"`
+	Type          ProductType `url:"type,omitempty"`
+	SKU           string      `url:"sku,omitempty"`
+	Featured      bool        `url:"featured,omitempty"`
+	Category      []int64     `url:"category,omitempty"`
+	Tag           []int64     `url:"tag,omitempty"`
+	ShippingClass string      `url:"shipping_class,omitempty"`
+	Attribute     string      `url:"attribute,omitempty"`
+	AttributeTerm string      `url:"attribute_term,omitempty"`
+	StockStatus   StockStatus `url:"stock_status,omitempty"`
 }
 
 // ProductBatchOption setting  operate for product in batch way
@@ -121,14 +240,71 @@ type ProductBatchResource struct {
 	Delete []*Product `json:"delete,omitempty"`
 }
 
+// ProductAttribute is an attribute attached to a product or a variation. Products
+// carry the list of Options, variations carry the single selected Option.
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#product-attributes-properties
 type ProductAttribute struct {
-	ID        int64                    `json:"id,omitempty"`
-	Name      string                   `json:"name,omitempty"`
-	Slug      string                   `json:"slug,omitempty"`
-	Position  int                      `json:"position,omitempty"`
-	Visible   bool                     `json:"visible,omitempty"`
-	Variation bool                     `json:"variation,omitempty"`
-	Options   []ProductAttributeOption `json:"options,omitempty"`
+	ID        int64    `json:"id,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	Slug      string   `json:"slug,omitempty"`
+	Position  int      `json:"position,omitempty"`
+	Visible   bool     `json:"visible,omitempty"`
+	Variation bool     `json:"variation,omitempty"`
+	Options   []string `json:"options,omitempty"`
+	Option    string   `json:"option,omitempty"`
+}
+
+// ProductDefaultAttribute is the attribute option preselected for a variable product
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#product-default-attributes-properties
+type ProductDefaultAttribute struct {
+	ID     int64  `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Option string `json:"option,omitempty"`
+}
+
+// ProductVariationIDs are the variations of a variable product. The v1 API
+// embeds whole variations instead of IDs, only their IDs are kept.
+type ProductVariationIDs []int64
+
+// UnmarshalJSON accepts both IDs and v1 variation objects
+func (ids *ProductVariationIDs) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		return nil
+	}
+	*ids = make(ProductVariationIDs, len(raw))
+	for i, item := range raw {
+		if err := json.Unmarshal(item, &(*ids)[i]); err == nil {
+			continue
+		}
+		var variation struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(item, &variation); err != nil {
+			return err
+		}
+		(*ids)[i] = variation.ID
+	}
+	return nil
+}
+
+// ProductCategoryRef is the category summary embedded in a product
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#product-categories-properties
+type ProductCategoryRef struct {
+	ID   int64  `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// ProductTagRef is the tag summary embedded in a product
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#product-tags-properties
+type ProductTagRef struct {
+	ID   int64  `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Slug string `json:"slug,omitempty"`
 }
 
 type ProductReview struct {
@@ -143,12 +319,6 @@ type ProductReview struct {
 	Hold          bool   `json:"hold,omitempty"`
 }
 
-type ProductAttributeOption struct {
-	Name   string `json:"name,omitempty"`
-	Slug   string `json:"slug,omitempty"`
-	Option string `json:"option,omitempty"`
-}
-
 type ProductImage struct {
 	ID              int64  `json:"id,omitempty"`
 	DateCreated     string `json:"date_created,omitempty"`
@@ -228,6 +398,70 @@ func (p *ProductServiceOp) Batch(data ProductBatchOption) (*ProductBatchResource
 	return resource, err
 }
 
+// GetBySKU returns the product whose SKU is exactly sku, or ErrSKUNotFound
+func (p *ProductServiceOp) GetBySKU(sku string) (*Product, error) {
+	products, err := p.List(ProductListOption{SKU: sku})
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		if products[i].SKU == sku {
+			return &products[i], nil
+		}
+	}
+	return nil, fmt.Errorf("product %q: %w", sku, ErrSKUNotFound)
+}
+
+// ListBySKUs returns the products having one of skus, querying up to a hundred
+// SKUs per request with a comma-separated sku filter. SKUs containing a comma
+// are queried on their own. The filter matches variations too, they come back
+// with Type "variation" and their product in ParentID.
+func (p *ProductServiceOp) ListBySKUs(skus []string) ([]Product, error) {
+	wanted := make(map[string]bool, len(skus))
+	var queries []string
+	var chunk []string
+	for _, sku := range skus {
+		if sku == "" || wanted[sku] {
+			continue
+		}
+		wanted[sku] = true
+		if strings.Contains(sku, ",") {
+			queries = append(queries, sku)
+			continue
+		}
+		chunk = append(chunk, sku)
+		if len(chunk) == maxBatchSize {
+			queries = append(queries, strings.Join(chunk, ","))
+			chunk = nil
+		}
+	}
+	if len(chunk) > 0 {
+		queries = append(queries, strings.Join(chunk, ","))
+	}
+
+	var products []Product
+	seen := map[int64]bool{}
+	for _, query := range queries {
+		for page := 1; ; page++ {
+			options := ProductListOption{ListOptions: ListOptions{Page: page, PerPage: maxBatchSize}, SKU: query}
+			matches, err := p.List(options)
+			if err != nil {
+				return products, err
+			}
+			for _, product := range matches {
+				if wanted[product.SKU] && !seen[product.ID] {
+					seen[product.ID] = true
+					products = append(products, product)
+				}
+			}
+			if len(matches) < maxBatchSize {
+				break
+			}
+		}
+	}
+	return products, nil
+}
+
 // extractPagination extracts pagination info from linkHeader.
 // Details on the format are here:
 // https://woocommerce.github.io/woocommerce-rest-api-docs/#pagination
@@ -243,7 +477,7 @@ func extractPagination(linkHeader string) (*Pagination, error) {
 	for _, link := range strings.Split(linkHeader, ",") {
 		match := linkRegex.FindStringSubmatch(link)
 		// Make sure the link is not empty or invalid
-		if len(match) != 4 {
+		if len(match) != 3 {
 			// We expect 3 values:
 			// match[0] = full match
 			// match[1] is the URL and match[2] is either 'previous' or 'next', 'first', 'last'
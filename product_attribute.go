@@ -25,6 +25,8 @@ type ProductAttributeData struct {
 	Order       int    `json:"order,omitempty"`
 	HasArchives bool   `json:"has_archives,omitempty"`
 	Visible     bool   `json:"visible,omitempty"`
+	// Error is set on the items of a batch response WooCommerce rejected.
+	Error *BatchItemError `json:"error,omitempty"`
 }
 
 type ProductAttributeListOption struct {
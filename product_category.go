@@ -8,6 +8,25 @@ const (
 	productCategoriesBasePath = "products/categories"
 )
 
+// CategoryDisplay is the archive display type of a product category
+type CategoryDisplay string
+
+const (
+	CategoryDisplayDefault       CategoryDisplay = "default"
+	CategoryDisplayProducts      CategoryDisplay = "products"
+	CategoryDisplaySubcategories CategoryDisplay = "subcategories"
+	CategoryDisplayBoth          CategoryDisplay = "both"
+)
+
+// Valid reports whether d is one of WooCommerce's built-in category display types
+func (d CategoryDisplay) Valid() bool {
+	switch d {
+	case CategoryDisplayDefault, CategoryDisplayProducts, CategoryDisplaySubcategories, CategoryDisplayBoth:
+		return true
+	}
+	return false
+}
+
 type ProductCategoryService interface {
 	Create(category ProductCategory) (*ProductCategory, error)
 	Get(categoryID int64, options interface{}) (*ProductCategory, error)
@@ -18,16 +37,18 @@ type ProductCategoryService interface {
 }
 
 type ProductCategory struct {
-	ID          int64        `json:"id,omitempty"`
-	Name        string       `json:"name,omitempty"`
-	Slug        string       `json:"slug,omitempty"`
-	ParentID    int64        `json:"parent,omitempty"`
-	Description string       `json:"description,omitempty"`
-	Display     string       `json:"display,omitempty"`
-	Image       ProductImage `json:"image,omitempty"`
-	MenuOrder   int          `json:"menu_order,omitempty"`
-	Count       int64        `json:"count,omitempty"`
-	Links       Links        `json:"_links,omitempty"`
+	ID          int64           `json:"id,omitempty"`
+	Name        string          `json:"name,omitempty"`
+	Slug        string          `json:"slug,omitempty"`
+	ParentID    int64           `json:"parent,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Display     CategoryDisplay `json:"display,omitempty"`
+	Image       ProductImage    `json:"image,omitempty"`
+	MenuOrder   int             `json:"menu_order,omitempty"`
+	Count       int64           `json:"count,omitempty"`
+	Links       Links           `json:"_links,omitempty"`
+	// Error is set on the items of a batch response WooCommerce rejected.
+	Error *BatchItemError `json:"error,omitempty"`
 }
 
 type ProductCategoryListOption struct {
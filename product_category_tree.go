@@ -0,0 +1,216 @@
+package woocommerce
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultCategoryPathSeparator separates category names in a breadcrumb path, as in "Apparel > Men > Shirts"
+const DefaultCategoryPathSeparator = ">"
+
+// ErrCategoryNotFound is returned when a category path does not resolve
+var ErrCategoryNotFound = errors.New("category not found")
+
+// CategoryTree is an in-memory view of the product category hierarchy
+type CategoryTree struct {
+	service ProductCategoryService
+	// Separator splits paths given to Resolve and EnsurePath, DefaultCategoryPathSeparator when empty.
+	Separator string
+
+	mu         sync.RWMutex
+	categories map[int64]ProductCategory
+	children   map[int64][]int64
+}
+
+// NewCategoryTree builds a tree from already loaded categories. The service is
+// only needed by EnsurePath and may be nil otherwise.
+func NewCategoryTree(service ProductCategoryService, categories []ProductCategory) *CategoryTree {
+	t := &CategoryTree{
+		service:    service,
+		categories: make(map[int64]ProductCategory, len(categories)),
+		children:   make(map[int64][]int64),
+	}
+	for _, category := range categories {
+		t.add(category)
+	}
+	return t
+}
+
+// LoadCategoryTree pages through every product category of the store and builds their tree
+func LoadCategoryTree(c *Client) (*CategoryTree, error) {
+	var all []ProductCategory
+	for page := 1; ; page++ {
+		options := ProductCategoryListOption{ListOptions: ListOptions{Page: page, PerPage: maxBatchSize}}
+		categories, err := c.ProductCategory.List(options)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, categories...)
+		if len(categories) < maxBatchSize {
+			break
+		}
+	}
+	return NewCategoryTree(c.ProductCategory, all), nil
+}
+
+// add inserts category, callers hold mu when needed
+func (t *CategoryTree) add(category ProductCategory) {
+	if _, exists := t.categories[category.ID]; !exists {
+		t.children[category.ParentID] = append(t.children[category.ParentID], category.ID)
+	}
+	t.categories[category.ID] = category
+}
+
+// Get returns the category with the given ID
+func (t *CategoryTree) Get(id int64) (ProductCategory, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	category, ok := t.categories[id]
+	return category, ok
+}
+
+// Parent returns the parent of the category, ok is false for top level categories
+func (t *CategoryTree) Parent(id int64) (ProductCategory, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	category, ok := t.categories[id]
+	if !ok || category.ParentID == 0 {
+		return ProductCategory{}, false
+	}
+	parent, ok := t.categories[category.ParentID]
+	return parent, ok
+}
+
+// Roots returns the top level categories ordered by menu order and name
+func (t *CategoryTree) Roots() []ProductCategory {
+	return t.Children(0)
+}
+
+// Children returns the direct children of the category ordered by menu order and name
+func (t *CategoryTree) Children(id int64) []ProductCategory {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	children := make([]ProductCategory, 0, len(t.children[id]))
+	for _, childID := range t.children[id] {
+		children = append(children, t.categories[childID])
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		if children[i].MenuOrder != children[j].MenuOrder {
+			return children[i].MenuOrder < children[j].MenuOrder
+		}
+		return children[i].Name < children[j].Name
+	})
+	return children
+}
+
+// Ancestors returns the ancestors of the category starting from its top level category
+func (t *CategoryTree) Ancestors(id int64) []ProductCategory {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var ancestors []ProductCategory
+	seen := map[int64]bool{id: true}
+	for category, ok := t.categories[id]; ok && category.ParentID != 0; {
+		if seen[category.ParentID] {
+			break // guard against corrupted parent loops
+		}
+		seen[category.ParentID] = true
+		category, ok = t.categories[category.ParentID]
+		if ok {
+			ancestors = append([]ProductCategory{category}, ancestors...)
+		}
+	}
+	return ancestors
+}
+
+// Path returns the breadcrumb path of the category, e.g. "Apparel > Men > Shirts"
+func (t *CategoryTree) Path(id int64) string {
+	category, ok := t.Get(id)
+	if !ok {
+		return ""
+	}
+	names := make([]string, 0, 4)
+	for _, ancestor := range t.Ancestors(id) {
+		names = append(names, html.UnescapeString(ancestor.Name))
+	}
+	names = append(names, html.UnescapeString(category.Name))
+	return strings.Join(names, " "+t.separator()+" ")
+}
+
+// Resolve returns the ID of the category at path. Names are matched case-insensitively
+// and with HTML entities decoded, as WooCommerce returns them escaped.
+func (t *CategoryTree) Resolve(path string) (int64, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	names := t.splitPath(path)
+	if len(names) == 0 {
+		return 0, fmt.Errorf("%w: empty path", ErrCategoryNotFound)
+	}
+	var parentID int64
+	for i, name := range names {
+		id, ok := t.findChild(parentID, name)
+		if !ok {
+			return 0, fmt.Errorf("%w: %q", ErrCategoryNotFound, strings.Join(names[:i+1], " "+t.separator()+" "))
+		}
+		parentID = id
+	}
+	return parentID, nil
+}
+
+// EnsurePath resolves path, creating the missing categories parent first through
+// ProductCategoryService, and returns the ID of its last category.
+func (t *CategoryTree) EnsurePath(path string) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	names := t.splitPath(path)
+	if len(names) == 0 {
+		return 0, fmt.Errorf("%w: empty path", ErrCategoryNotFound)
+	}
+	var parentID int64
+	for _, name := range names {
+		if id, ok := t.findChild(parentID, name); ok {
+			parentID = id
+			continue
+		}
+		if t.service == nil {
+			return 0, errors.New("category tree has no service to create categories with")
+		}
+		created, err := t.service.Create(ProductCategory{Name: name, ParentID: parentID})
+		if err != nil {
+			return 0, err
+		}
+		t.add(*created)
+		parentID = created.ID
+	}
+	return parentID, nil
+}
+
+// findChild looks a category up by name under parentID, callers hold mu
+func (t *CategoryTree) findChild(parentID int64, name string) (int64, bool) {
+	for _, childID := range t.children[parentID] {
+		if strings.EqualFold(html.UnescapeString(t.categories[childID].Name), name) {
+			return childID, true
+		}
+	}
+	return 0, false
+}
+
+func (t *CategoryTree) separator() string {
+	if t.Separator == "" {
+		return DefaultCategoryPathSeparator
+	}
+	return t.Separator
+}
+
+func (t *CategoryTree) splitPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, t.separator()) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
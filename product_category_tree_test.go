@@ -0,0 +1,75 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func testCategories() []ProductCategory {
+	return []ProductCategory{
+		{ID: 1, Name: "Apparel"},
+		{ID: 2, Name: "Men", ParentID: 1},
+		{ID: 3, Name: "Women", ParentID: 1},
+		{ID: 4, Name: "Shirts &amp; Tops", ParentID: 2},
+		{ID: 5, Name: "Men", ParentID: 0},
+	}
+}
+
+func TestCategoryTree_Lookups(t *testing.T) {
+	tree := NewCategoryTree(nil, testCategories())
+
+	if parent, ok := tree.Parent(4); !ok || parent.ID != 2 {
+		t.Errorf("Parent(4) = %+v, %v", parent, ok)
+	}
+	if children := tree.Children(1); len(children) != 2 || children[0].Name != "Men" {
+		t.Errorf("Children(1) = %+v", children)
+	}
+	if ancestors := tree.Ancestors(4); len(ancestors) != 2 || ancestors[0].ID != 1 || ancestors[1].ID != 2 {
+		t.Errorf("Ancestors(4) = %+v", ancestors)
+	}
+	if path := tree.Path(4); path != "Apparel > Men > Shirts & Tops" {
+		t.Errorf("Path(4) = %q", path)
+	}
+	if id, err := tree.Resolve("apparel>men > shirts & tops"); err != nil || id != 4 {
+		t.Errorf("Resolve = %d, %v", id, err)
+	}
+	if id, err := tree.Resolve("Men"); err != nil || id != 5 {
+		t.Errorf("Resolve(Men) = %d, %v", id, err)
+	}
+	if _, err := tree.Resolve("Apparel > Kids"); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("Resolve(missing) err = %v", err)
+	}
+}
+
+func TestCategoryTree_EnsurePath(t *testing.T) {
+	var created []ProductCategory
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products/categories", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(testCategories())
+			return
+		}
+		var category ProductCategory
+		json.NewDecoder(r.Body).Decode(&category)
+		category.ID = int64(100 + len(created))
+		created = append(created, category)
+		json.NewEncoder(w).Encode(category)
+	})
+	tree, err := LoadCategoryTree(newTestClient(t, mux))
+	if err != nil {
+		t.Fatalf("LoadCategoryTree: %v", err)
+	}
+
+	id, err := tree.EnsurePath("Apparel > Kids > Shoes")
+	if err != nil {
+		t.Fatalf("EnsurePath: %v", err)
+	}
+	if len(created) != 2 || created[0].ParentID != 1 || created[1].ParentID != created[0].ID || id != created[1].ID {
+		t.Errorf("created %+v, id %d", created, id)
+	}
+	if again, err := tree.EnsurePath("Apparel > Kids > Shoes"); err != nil || again != id || len(created) != 2 {
+		t.Errorf("second EnsurePath = %d, %v, created %d", again, err, len(created))
+	}
+}
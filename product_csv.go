@@ -0,0 +1,1150 @@
+package woocommerce
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Columns of WooCommerce's built-in product CSV exporter, in its order. The unit
+// columns are completed with the store's weight and dimension units.
+var productCSVColumns = []string{
+	"ID", "Type", "SKU", "Name", "Published", "Is featured?", "Visibility in catalog",
+	"Short description", "Description", "Date sale price starts", "Date sale price ends",
+	"Tax status", "Tax class", "In stock?", "Stock", "Low stock amount", "Backorders allowed?",
+	"Sold individually?", "Weight (%s)", "Length (%s)", "Width (%s)", "Height (%s)",
+	"Allow customer reviews?", "Purchase note", "Sale price", "Regular price", "Categories",
+	"Tags", "Shipping class", "Images", "Download limit", "Download expiry days", "Parent",
+	"Grouped products", "Upsells", "Cross-sells", "External URL", "Button text", "Position",
+}
+
+var (
+	csvUnitSuffix       = regexp.MustCompile(` \(([^)]*)\)$`)
+	csvAttributeColumn  = regexp.MustCompile(`^attribute (\d+) (name|value\(s\)|visible|global|default)$`)
+	csvDownloadColumn   = regexp.MustCompile(`^download (\d+) (id|name|url)$`)
+	csvMetaColumnPrefix = "meta: "
+)
+
+const csvTypeVariation = "variation"
+
+// ProductCSVRow is one row of a WooCommerce product CSV. Columns that reference
+// other objects by name or SKU are kept apart from Product until they are resolved.
+type ProductCSVRow struct {
+	// Line is the line of the row in the file it was read from.
+	Line    int
+	Product Product
+	// Variation is set for rows of type "variation", whose fields are held in Product.
+	Variation bool
+	// Parent is the parent of a variation, "id:123" or the parent's SKU.
+	Parent string
+	// Categories are category paths, e.g. "Apparel > Men".
+	Categories      []string
+	Tags            []string
+	GroupedProducts []string
+	Upsells         []string
+	CrossSells      []string
+
+	// flags holds the JSON names of the boolean columns set in the row, which
+	// are sent even when false so that an import can clear them.
+	flags map[string]bool
+}
+
+// ProductCSV reads and writes the column layout of WooCommerce's built-in
+// product CSV exporter and importer, and syncs such files with the store.
+type ProductCSV struct {
+	client *Client
+	// WeightUnit and DimensionUnit are the units of the "Weight (kg)" style headers.
+	// ReadRows fails on files in other units and sets them from the file when
+	// empty, Import and Export read them from the store settings when empty.
+	WeightUnit    string
+	DimensionUnit string
+
+	categories *CategoryTree
+	tags       map[string]int64
+	attributes map[string]int64
+	skus       map[string]int64
+	variations map[int64][]ProductVariation
+}
+
+// NewProductCSV returns a ProductCSV syncing with the client's store
+func NewProductCSV(c *Client) *ProductCSV {
+	return &ProductCSV{client: c}
+}
+
+// ReadRows parses a product CSV. Column headers are matched case-insensitively,
+// unknown columns are ignored. Weights and dimensions are kept as written, so
+// their units in parentheses must match WeightUnit and DimensionUnit.
+func (p *ProductCSV) ReadRows(r io.Reader) ([]ProductCSVRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	if err := p.checkUnits(header); err != nil {
+		return nil, err
+	}
+
+	var rows []ProductCSVRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return rows, err
+		}
+		row, err := parseProductCSVRecord(header, record)
+		if err != nil {
+			return rows, fmt.Errorf("line %d: %w", line, err)
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+}
+
+// checkUnits compares the units of the weight and dimension headers with the
+// configured ones, adopting them when none are configured
+func (p *ProductCSV) checkUnits(header []string) error {
+	for _, column := range header {
+		match := csvUnitSuffix.FindStringSubmatch(strings.TrimSpace(column))
+		if match == nil {
+			continue
+		}
+		var unit *string
+		switch strings.ToLower(strings.TrimSuffix(strings.TrimSpace(column), match[0])) {
+		case "weight":
+			unit = &p.WeightUnit
+		case "length", "width", "height":
+			unit = &p.DimensionUnit
+		default:
+			continue
+		}
+		if *unit == "" {
+			*unit = match[1]
+		} else if !strings.EqualFold(*unit, match[1]) {
+			return fmt.Errorf("column %q: values are in %s, expected %s", column, match[1], *unit)
+		}
+	}
+	return nil
+}
+
+// loadUnits reads the unset WeightUnit and DimensionUnit from the store settings
+func (p *ProductCSV) loadUnits() error {
+	for _, setting := range []struct {
+		id   string
+		unit *string
+	}{
+		{"woocommerce_weight_unit", &p.WeightUnit},
+		{"woocommerce_dimension_unit", &p.DimensionUnit},
+	} {
+		if *setting.unit != "" {
+			continue
+		}
+		var resource struct {
+			Value string `json:"value"`
+		}
+		if err := p.client.Get("settings/products/"+setting.id, &resource, nil); err != nil {
+			return err
+		}
+		*setting.unit = resource.Value
+	}
+	return nil
+}
+
+func parseProductCSVRecord(header, record []string) (ProductCSVRow, error) {
+	row := ProductCSVRow{flags: map[string]bool{}}
+	product := &row.Product
+	attributes := map[int]*ProductAttribute{}
+	defaults := map[int]string{}
+	downloads := map[int]*ProductDownload{}
+	attribute := func(n int) *ProductAttribute {
+		if attributes[n] == nil {
+			attributes[n] = &ProductAttribute{Position: n - 1}
+		}
+		return attributes[n]
+	}
+	download := func(n int) *ProductDownload {
+		if downloads[n] == nil {
+			downloads[n] = &ProductDownload{}
+		}
+		return downloads[n]
+	}
+
+	for i, column := range header {
+		if i >= len(record) {
+			break
+		}
+		value := strings.TrimSpace(record[i])
+		key := strings.ToLower(strings.TrimSpace(csvUnitSuffix.ReplaceAllString(strings.TrimSpace(column), "")))
+		if value == "" && key != "published" {
+			continue
+		}
+		var err error
+		switch key {
+		case "id":
+			product.ID, err = strconv.ParseInt(value, 10, 64)
+		case "type":
+			for _, t := range splitCSVList(value) {
+				switch strings.ToLower(t) {
+				case csvTypeVariation:
+					row.Variation = true
+				case "virtual":
+					product.Virtual = true
+				case "downloadable":
+					product.Downloadable = true
+				default:
+					product.Type = ProductType(strings.ToLower(t))
+				}
+			}
+		case "sku":
+			product.SKU = value
+		case "name":
+			product.Name = value
+		case "published":
+			switch value {
+			case "1":
+				product.Status = ProductStatusPublish
+			case "-1":
+				product.Status = ProductStatusPrivate
+			case "0":
+				product.Status = ProductStatusDraft
+			}
+		case "is featured?":
+			product.Featured = csvBool(value)
+			row.flags["featured"] = true
+		case "visibility in catalog":
+			product.CatalogVisibility = CatalogVisibility(value)
+		case "short description":
+			product.ShortDescription = value
+		case "description":
+			product.Description = value
+		case "date sale price starts":
+			product.DateOnSaleFrom = strings.Replace(value, " ", "T", 1)
+		case "date sale price ends":
+			product.DateOnSaleTo = strings.Replace(value, " ", "T", 1)
+		case "tax status":
+			product.TaxStatus = value
+		case "tax class":
+			product.TaxClass = value
+		case "in stock?":
+			switch strings.ToLower(value) {
+			case "1":
+				product.StockStatus = StockStatusInStock
+			case "0":
+				product.StockStatus = StockStatusOutOfStock
+			case "backorder":
+				product.StockStatus = StockStatusOnBackorder
+			}
+		case "stock":
+			product.ManageStock = true
+			product.StockQuantity, err = csvInt64(value)
+		case "low stock amount":
+			product.LowStockAmount, err = csvInt64(value)
+		case "backorders allowed?":
+			switch strings.ToLower(value) {
+			case "1":
+				product.Backorders = BackorderPolicyYes
+			case "0":
+				product.Backorders = BackorderPolicyNo
+			case "notify":
+				product.Backorders = BackorderPolicyNotify
+			}
+		case "sold individually?":
+			product.SoldIndividually = csvBool(value)
+			row.flags["sold_individually"] = true
+		case "weight":
+			product.Weight = value
+		case "length", "width", "height":
+			if product.Dimensions == nil {
+				product.Dimensions = map[string]string{}
+			}
+			product.Dimensions[key] = value
+		case "allow customer reviews?":
+			product.ReviewsAllowed = csvBool(value)
+			row.flags["reviews_allowed"] = true
+		case "purchase note":
+			product.PurchaseNote = value
+		case "sale price":
+			product.SalePrice = value
+		case "regular price":
+			product.RegularPrice = value
+		case "categories":
+			row.Categories = splitCSVList(value)
+		case "tags":
+			row.Tags = splitCSVList(value)
+		case "shipping class":
+			product.ShippingClass = value
+		case "images":
+			for _, src := range splitCSVList(value) {
+				product.Images = append(product.Images, ProductImage{Src: src})
+			}
+		case "download limit":
+			product.DownloadLimit, err = strconv.ParseInt(value, 10, 64)
+		case "download expiry days":
+			product.DownloadExpiry, err = strconv.ParseInt(value, 10, 64)
+		case "parent":
+			row.Parent = value
+		case "grouped products":
+			row.GroupedProducts = splitCSVList(value)
+		case "upsells":
+			row.Upsells = splitCSVList(value)
+		case "cross-sells":
+			row.CrossSells = splitCSVList(value)
+		case "external url":
+			product.ExternalUrl = value
+		case "button text":
+			product.ButtonText = value
+		case "position":
+			product.MenuOrder, err = strconv.Atoi(value)
+		default:
+			if match := csvAttributeColumn.FindStringSubmatch(key); match != nil {
+				n, _ := strconv.Atoi(match[1])
+				switch match[2] {
+				case "name":
+					attribute(n).Name = value
+				case "value(s)":
+					attribute(n).Options = splitCSVList(value)
+				case "visible":
+					attribute(n).Visible = csvBool(value)
+				case "global":
+					// global attributes are resolved to their ID on import, a
+					// negative placeholder marks them until then
+					if csvBool(value) {
+						attribute(n).ID = -1
+					}
+				case "default":
+					defaults[n] = value
+				}
+			} else if match := csvDownloadColumn.FindStringSubmatch(key); match != nil {
+				n, _ := strconv.Atoi(match[1])
+				switch match[2] {
+				case "id":
+					download(n).ID, err = strconv.ParseInt(value, 10, 64)
+				case "name":
+					download(n).Name = value
+				case "url":
+					download(n).File = value
+				}
+			} else if strings.HasPrefix(key, csvMetaColumnPrefix) {
+				metaKey := strings.TrimSpace(strings.TrimSpace(column)[len(csvMetaColumnPrefix):])
+				product.MetaData = append(product.MetaData, MetaData{Key: metaKey, Value: value})
+			}
+		}
+		if err != nil {
+			return row, fmt.Errorf("column %q: %w", column, err)
+		}
+	}
+
+	for _, n := range sortedKeys(attributes) {
+		a := attributes[n]
+		if a.Name == "" {
+			continue
+		}
+		if row.Variation {
+			if len(a.Options) > 0 {
+				a.Option = a.Options[0]
+			}
+			a.Options = nil
+		} else if product.Type == ProductTypeVariable {
+			a.Variation = true
+		}
+		product.Attributes = append(product.Attributes, *a)
+		if option, ok := defaults[n]; ok {
+			product.DefaultAttributes = append(product.DefaultAttributes, ProductDefaultAttribute{ID: a.ID, Name: a.Name, Option: option})
+		}
+	}
+	for _, n := range sortedKeys(downloads) {
+		product.Downloads = append(product.Downloads, *downloads[n])
+	}
+	return row, nil
+}
+
+// WriteRows writes rows in the layout of WooCommerce's product CSV exporter. Attribute,
+// download and meta columns are added as needed by the rows. Unset units are
+// written as WooCommerce's defaults, kg and cm.
+func (p *ProductCSV) WriteRows(w io.Writer, rows []ProductCSVRow) error {
+	attributeCount, downloadCount := 0, 0
+	metaKeys := map[string]bool{}
+	for _, row := range rows {
+		if len(row.Product.Attributes) > attributeCount {
+			attributeCount = len(row.Product.Attributes)
+		}
+		if len(row.Product.Downloads) > downloadCount {
+			downloadCount = len(row.Product.Downloads)
+		}
+		for _, meta := range row.Product.MetaData {
+			if _, ok := csvMetaValue(meta.Value); ok && meta.Key != "" && !strings.HasPrefix(meta.Key, "_") {
+				metaKeys[meta.Key] = true
+			}
+		}
+	}
+	sortedMetaKeys := make([]string, 0, len(metaKeys))
+	for key := range metaKeys {
+		sortedMetaKeys = append(sortedMetaKeys, key)
+	}
+	sort.Strings(sortedMetaKeys)
+
+	weightUnit, dimensionUnit := p.WeightUnit, p.DimensionUnit
+	if weightUnit == "" {
+		weightUnit = "kg"
+	}
+	if dimensionUnit == "" {
+		dimensionUnit = "cm"
+	}
+	header := make([]string, 0, len(productCSVColumns)+5*attributeCount+3*downloadCount+len(sortedMetaKeys))
+	for _, column := range productCSVColumns {
+		switch column {
+		case "Weight (%s)":
+			column = fmt.Sprintf(column, weightUnit)
+		case "Length (%s)", "Width (%s)", "Height (%s)":
+			column = fmt.Sprintf(column, dimensionUnit)
+		}
+		header = append(header, column)
+	}
+	for i := 1; i <= downloadCount; i++ {
+		header = append(header, fmt.Sprintf("Download %d ID", i), fmt.Sprintf("Download %d name", i), fmt.Sprintf("Download %d URL", i))
+	}
+	for i := 1; i <= attributeCount; i++ {
+		header = append(header, fmt.Sprintf("Attribute %d name", i), fmt.Sprintf("Attribute %d value(s)", i),
+			fmt.Sprintf("Attribute %d visible", i), fmt.Sprintf("Attribute %d global", i), fmt.Sprintf("Attribute %d default", i))
+	}
+	for _, key := range sortedMetaKeys {
+		header = append(header, "Meta: "+key)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(formatProductCSVRecord(row, attributeCount, downloadCount, sortedMetaKeys)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatProductCSVRecord(row ProductCSVRow, attributeCount, downloadCount int, metaKeys []string) []string {
+	product := row.Product
+
+	types := []string{string(product.Type)}
+	if row.Variation {
+		types = []string{csvTypeVariation}
+	}
+	if product.Downloadable {
+		types = append(types, "downloadable")
+	}
+	if product.Virtual {
+		types = append(types, "virtual")
+	}
+
+	published := "0"
+	switch product.Status {
+	case ProductStatusPublish:
+		published = "1"
+	case ProductStatusPrivate:
+		published = "-1"
+	}
+
+	inStock := ""
+	switch product.StockStatus {
+	case StockStatusInStock:
+		inStock = "1"
+	case StockStatusOutOfStock:
+		inStock = "0"
+	case StockStatusOnBackorder:
+		inStock = "backorder"
+	}
+
+	backorders := ""
+	switch product.Backorders {
+	case BackorderPolicyYes:
+		backorders = "1"
+	case BackorderPolicyNo:
+		backorders = "0"
+	case BackorderPolicyNotify:
+		backorders = "notify"
+	}
+
+	stock := ""
+	if product.ManageStock && product.StockQuantity != nil {
+		stock = strconv.FormatInt(*product.StockQuantity, 10)
+	}
+	lowStock := ""
+	if product.LowStockAmount != nil {
+		lowStock = strconv.FormatInt(*product.LowStockAmount, 10)
+	}
+
+	// cells left empty in a file that was read stay empty
+	flag := func(name string, value bool) string {
+		if row.flags != nil && !row.flags[name] {
+			return ""
+		}
+		return csvBoolString(value)
+	}
+
+	images := make([]string, 0, len(product.Images))
+	for _, image := range product.Images {
+		images = append(images, image.Src)
+	}
+
+	record := []string{
+		csvInt(product.ID),
+		strings.Join(types, ", "),
+		product.SKU,
+		product.Name,
+		published,
+		flag("featured", product.Featured),
+		string(product.CatalogVisibility),
+		product.ShortDescription,
+		product.Description,
+		product.DateOnSaleFrom,
+		product.DateOnSaleTo,
+		product.TaxStatus,
+		product.TaxClass,
+		inStock,
+		stock,
+		lowStock,
+		backorders,
+		flag("sold_individually", product.SoldIndividually),
+		product.Weight,
+		product.Dimensions["length"],
+		product.Dimensions["width"],
+		product.Dimensions["height"],
+		flag("reviews_allowed", product.ReviewsAllowed),
+		product.PurchaseNote,
+		product.SalePrice,
+		product.RegularPrice,
+		joinCSVList(row.Categories),
+		joinCSVList(row.Tags),
+		product.ShippingClass,
+		joinCSVList(images),
+		csvInt(product.DownloadLimit),
+		csvInt(product.DownloadExpiry),
+		row.Parent,
+		joinCSVList(row.GroupedProducts),
+		joinCSVList(row.Upsells),
+		joinCSVList(row.CrossSells),
+		product.ExternalUrl,
+		product.ButtonText,
+		strconv.Itoa(product.MenuOrder),
+	}
+
+	for i := 0; i < downloadCount; i++ {
+		if i < len(product.Downloads) {
+			d := product.Downloads[i]
+			record = append(record, csvInt(d.ID), d.Name, d.File)
+		} else {
+			record = append(record, "", "", "")
+		}
+	}
+	for i := 0; i < attributeCount; i++ {
+		if i >= len(product.Attributes) {
+			record = append(record, "", "", "", "", "")
+			continue
+		}
+		a := product.Attributes[i]
+		values := a.Options
+		if row.Variation {
+			values = []string{a.Option}
+		}
+		defaultOption := ""
+		for _, d := range product.DefaultAttributes {
+			if (d.ID != 0 && d.ID == a.ID) || (d.ID == 0 && strings.EqualFold(d.Name, a.Name)) {
+				defaultOption = d.Option
+			}
+		}
+		visible := csvBoolString(a.Visible)
+		if row.Variation {
+			visible = ""
+		}
+		record = append(record, a.Name, joinCSVList(values), visible, csvBoolString(a.ID != 0), defaultOption)
+	}
+	for _, key := range metaKeys {
+		value := ""
+		for _, meta := range product.MetaData {
+			if meta.Key == key {
+				value, _ = csvMetaValue(meta.Value)
+			}
+		}
+		record = append(record, value)
+	}
+	return record
+}
+
+// ProductCSVRowError reports a row that could not be imported
+type ProductCSVRowError struct {
+	Line int
+	SKU  string
+	Err  error
+}
+
+func (e ProductCSVRowError) Error() string {
+	return fmt.Sprintf("line %d (sku %q): %v", e.Line, e.SKU, e.Err)
+}
+
+func (e ProductCSVRowError) Unwrap() error {
+	return e.Err
+}
+
+// ProductCSVImportResult summarises an Import
+type ProductCSVImportResult struct {
+	Created    int
+	Updated    int
+	Products   []Product
+	Variations []ProductVariation
+	// Errors holds the rows that failed, the other rows are still imported.
+	Errors []ProductCSVRowError
+}
+
+// Import reads a product CSV and creates or updates its products and variations.
+// The file must be in the store's units. Rows are matched to existing objects by
+// ID, then by SKU. Categories are created
+// along their paths and tags by name when missing. Parents, grouped products,
+// upsells and cross-sells may be referenced as "id:123" or by SKU and must appear
+// earlier in the file or already exist in the store. Images already attached to
+// the updated product, or to a variation of the same parent, are matched by URL.
+func (p *ProductCSV) Import(r io.Reader) (*ProductCSVImportResult, error) {
+	if err := p.loadUnits(); err != nil {
+		return nil, err
+	}
+	rows, err := p.ReadRows(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.loadReferences(); err != nil {
+		return nil, err
+	}
+
+	result := &ProductCSVImportResult{}
+	for _, row := range rows {
+		if err := p.importRow(row, result); err != nil {
+			result.Errors = append(result.Errors, ProductCSVRowError{Line: row.Line, SKU: row.Product.SKU, Err: err})
+		}
+	}
+	return result, nil
+}
+
+func (p *ProductCSV) loadReferences() error {
+	if p.categories == nil {
+		tree, err := LoadCategoryTree(p.client)
+		if err != nil {
+			return err
+		}
+		p.categories = tree
+	}
+	if p.tags == nil {
+		p.tags = map[string]int64{}
+		for page := 1; ; page++ {
+			tags, err := p.client.ProductTag.List(ProductTagListOption{ListOptions: ListOptions{Page: page, PerPage: maxBatchSize}})
+			if err != nil {
+				return err
+			}
+			for _, tag := range tags {
+				p.tags[strings.ToLower(tag.Name)] = tag.ID
+			}
+			if len(tags) < maxBatchSize {
+				break
+			}
+		}
+	}
+	if p.attributes == nil {
+		attributes, err := p.client.ProductAttribute.List(nil)
+		if err != nil {
+			return err
+		}
+		p.attributes = map[string]int64{}
+		for _, attribute := range attributes {
+			p.attributes[strings.ToLower(attribute.Name)] = attribute.ID
+		}
+	}
+	if p.skus == nil {
+		p.skus = map[string]int64{}
+	}
+	if p.variations == nil {
+		p.variations = map[int64][]ProductVariation{}
+	}
+	return nil
+}
+
+func (p *ProductCSV) importRow(row ProductCSVRow, result *ProductCSVImportResult) error {
+	product := row.Product
+	for i := range product.Attributes {
+		if err := p.resolveAttribute(&product.Attributes[i].ID, product.Attributes[i].Name); err != nil {
+			return err
+		}
+	}
+	for i := range product.DefaultAttributes {
+		if err := p.resolveAttribute(&product.DefaultAttributes[i].ID, product.DefaultAttributes[i].Name); err != nil {
+			return err
+		}
+	}
+
+	if row.Variation {
+		return p.importVariation(row, product, result)
+	}
+
+	for _, path := range row.Categories {
+		id, err := p.categories.EnsurePath(path)
+		if err != nil {
+			return err
+		}
+		product.Categories = append(product.Categories, ProductCategoryRef{ID: id})
+	}
+	for _, name := range row.Tags {
+		id, err := p.resolveTag(name)
+		if err != nil {
+			return err
+		}
+		product.Tags = append(product.Tags, ProductTagRef{ID: id})
+	}
+	var err error
+	if product.GroupedProducts, err = p.resolveProductRefs(row.GroupedProducts); err != nil {
+		return err
+	}
+	if product.UpsellIDs, err = p.resolveProductRefs(row.Upsells); err != nil {
+		return err
+	}
+	if product.CrossSellIDs, err = p.resolveProductRefs(row.CrossSells); err != nil {
+		return err
+	}
+
+	if product.ID == 0 && product.SKU != "" {
+		if product.ID, err = p.findProductBySKU(product.SKU); err != nil {
+			return err
+		}
+	}
+	var saved *Product
+	updating := product.ID != 0
+	if updating && len(product.Images) > 0 {
+		existing, err := p.client.Product.Get(product.ID, nil)
+		if err != nil {
+			return err
+		}
+		product.Images = reuseImages(product.Images, existing.Images)
+	}
+	if updating {
+		saved = new(Product)
+		update := productCSVUpdate{Product: &product}
+		if row.flags["featured"] {
+			update.Featured = &product.Featured
+		}
+		if row.flags["sold_individually"] {
+			update.SoldIndividually = &product.SoldIndividually
+		}
+		if row.flags["reviews_allowed"] {
+			update.ReviewsAllowed = &product.ReviewsAllowed
+		}
+		err = p.client.Put(fmt.Sprintf("%s/%d", productsBasePath, product.ID), update, saved)
+	} else {
+		saved, err = p.client.Product.Create(product)
+	}
+	if err != nil {
+		return err
+	}
+	if updating {
+		result.Updated++
+	} else {
+		result.Created++
+	}
+	if saved.SKU != "" {
+		p.skus[saved.SKU] = saved.ID
+	}
+	result.Products = append(result.Products, *saved)
+	return nil
+}
+
+func (p *ProductCSV) importVariation(row ProductCSVRow, product Product, result *ProductCSVImportResult) error {
+	if row.Parent == "" {
+		return fmt.Errorf("variation has no parent")
+	}
+	parentIDs, err := p.resolveProductRefs([]string{row.Parent})
+	if err != nil {
+		return err
+	}
+	parentID := parentIDs[0]
+	variation := productToVariation(product)
+
+	if (variation.ID == 0 && variation.SKU != "") || variation.Image != nil {
+		existing, ok := p.variations[parentID]
+		if !ok {
+			if existing, err = NewVariationMatrix(p.client, nil).listAll(parentID); err != nil {
+				return err
+			}
+			p.variations[parentID] = existing
+		}
+		var images []ProductImage
+		for _, candidate := range existing {
+			if variation.ID == 0 && variation.SKU != "" && candidate.SKU == variation.SKU {
+				variation.ID = candidate.ID
+			}
+			if candidate.Image != nil {
+				images = append(images, *candidate.Image)
+			}
+		}
+		if variation.Image != nil {
+			variation.Image = &reuseImages([]ProductImage{*variation.Image}, images)[0]
+		}
+	}
+
+	var saved *ProductVariation
+	updating := variation.ID != 0
+	if updating {
+		saved = new(ProductVariation)
+		update := variationCSVUpdate{ProductVariation: &variation}
+		if row.flags["sold_individually"] {
+			update.SoldIndividually = &variation.SoldIndividually
+		}
+		err = p.client.Put(fmt.Sprintf("%s/%d", fmt.Sprintf(productVariationsBasePath, parentID), variation.ID), update, saved)
+	} else {
+		saved, err = p.client.ProductVariation.Create(parentID, variation)
+	}
+	if err != nil {
+		return err
+	}
+	if updating {
+		result.Updated++
+	} else {
+		result.Created++
+	}
+	if saved.SKU != "" {
+		p.skus[saved.SKU] = saved.ID
+	}
+	result.Variations = append(result.Variations, *saved)
+	return nil
+}
+
+// productCSVUpdate sends the boolean columns of a row even when they are false,
+// Product leaves them out of its JSON
+type productCSVUpdate struct {
+	*Product
+	Featured         *bool `json:"featured,omitempty"`
+	SoldIndividually *bool `json:"sold_individually,omitempty"`
+	ReviewsAllowed   *bool `json:"reviews_allowed,omitempty"`
+}
+
+// variationCSVUpdate is productCSVUpdate for variations
+type variationCSVUpdate struct {
+	*ProductVariation
+	SoldIndividually *bool `json:"sold_individually,omitempty"`
+}
+
+// reuseImages refers to the images already in existing by their ID, WooCommerce
+// would otherwise download them again as new media
+func reuseImages(images, existing []ProductImage) []ProductImage {
+	ids := map[string]int64{}
+	for _, image := range existing {
+		if image.Src != "" && image.ID != 0 {
+			ids[image.Src] = image.ID
+		}
+	}
+	reused := make([]ProductImage, len(images))
+	for i, image := range images {
+		reused[i] = image
+		if id, ok := ids[image.Src]; ok && image.ID == 0 {
+			reused[i] = ProductImage{ID: id}
+		}
+	}
+	return reused
+}
+
+// resolveAttribute replaces the global attribute placeholder ID set by ReadRows
+func (p *ProductCSV) resolveAttribute(id *int64, name string) error {
+	if *id >= 0 {
+		return nil
+	}
+	resolved, ok := p.attributes[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("global attribute %q does not exist", name)
+	}
+	*id = resolved
+	return nil
+}
+
+func (p *ProductCSV) resolveTag(name string) (int64, error) {
+	if id, ok := p.tags[strings.ToLower(name)]; ok {
+		return id, nil
+	}
+	tag, err := p.client.ProductTag.Create(ProductTag{Name: name})
+	if err != nil {
+		return 0, err
+	}
+	p.tags[strings.ToLower(name)] = tag.ID
+	return tag.ID, nil
+}
+
+// resolveProductRefs turns "id:123" and SKU references into product IDs
+func (p *ProductCSV) resolveProductRefs(refs []string) ([]int64, error) {
+	ids := make([]int64, 0, len(refs))
+	for _, ref := range refs {
+		if strings.HasPrefix(ref, "id:") {
+			id, err := strconv.ParseInt(strings.TrimPrefix(ref, "id:"), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid product reference %q", ref)
+			}
+			ids = append(ids, id)
+			continue
+		}
+		id, err := p.findProductBySKU(ref)
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			return nil, fmt.Errorf("no product with sku %q", ref)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// findProductBySKU returns the ID of the product with sku, or 0 when there is none
+func (p *ProductCSV) findProductBySKU(sku string) (int64, error) {
+	if id, ok := p.skus[sku]; ok {
+		return id, nil
+	}
+	product, err := p.client.Product.GetBySKU(sku)
+	if errors.Is(err, ErrSKUNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	p.skus[sku] = product.ID
+	return product.ID, nil
+}
+
+// Export writes every product of the store, each variable product followed by its
+// variations, in the layout of WooCommerce's product CSV exporter.
+func (p *ProductCSV) Export(w io.Writer) error {
+	if err := p.loadUnits(); err != nil {
+		return err
+	}
+	tree, err := LoadCategoryTree(p.client)
+	if err != nil {
+		return err
+	}
+
+	var rows []ProductCSVRow
+	for page := 1; ; page++ {
+		products, err := p.client.Product.List(ProductListOption{ListOptions: ListOptions{Page: page, PerPage: maxBatchSize}})
+		if err != nil {
+			return err
+		}
+		for _, product := range products {
+			rows = append(rows, productCSVRowFor(product, tree))
+			if product.Type != ProductTypeVariable {
+				continue
+			}
+			variations, err := NewVariationMatrix(p.client, nil).listAll(product.ID)
+			if err != nil {
+				return err
+			}
+			for _, variation := range variations {
+				rows = append(rows, ProductCSVRow{
+					Product:   variationToProduct(variation),
+					Variation: true,
+					Parent:    fmt.Sprintf("id:%d", product.ID),
+				})
+			}
+		}
+		if len(products) < maxBatchSize {
+			break
+		}
+	}
+	return p.WriteRows(w, rows)
+}
+
+func productCSVRowFor(product Product, tree *CategoryTree) ProductCSVRow {
+	row := ProductCSVRow{Product: product}
+	for _, category := range product.Categories {
+		path := tree.Path(category.ID)
+		if path == "" {
+			path = category.Name
+		}
+		row.Categories = append(row.Categories, path)
+	}
+	for _, tag := range product.Tags {
+		row.Tags = append(row.Tags, tag.Name)
+	}
+	row.GroupedProducts = idRefs(product.GroupedProducts)
+	row.Upsells = idRefs(product.UpsellIDs)
+	row.CrossSells = idRefs(product.CrossSellIDs)
+	return row
+}
+
+func productToVariation(p Product) ProductVariation {
+	v := ProductVariation{
+		ID:               p.ID,
+		Description:      p.Description,
+		SKU:              p.SKU,
+		RegularPrice:     p.RegularPrice,
+		SalePrice:        p.SalePrice,
+		DateOnSaleFrom:   p.DateOnSaleFrom,
+		DateOnSaleTo:     p.DateOnSaleTo,
+		Status:           p.Status,
+		Virtual:          p.Virtual,
+		Downloadable:     p.Downloadable,
+		Downloads:        p.Downloads,
+		DownloadLimit:    p.DownloadLimit,
+		DownloadExpiry:   p.DownloadExpiry,
+		TaxStatus:        p.TaxStatus,
+		TaxClass:         p.TaxClass,
+		ManageStock:      p.ManageStock,
+		StockQuantity:    p.StockQuantity,
+		StockStatus:      p.StockStatus,
+		Backorders:       p.Backorders,
+		LowStockAmount:   p.LowStockAmount,
+		SoldIndividually: p.SoldIndividually,
+		Weight:           p.Weight,
+		Dimensions:       p.Dimensions,
+		ShippingClass:    p.ShippingClass,
+		Attributes:       p.Attributes,
+		MetaData:         p.MetaData,
+		MenuOrder:        p.MenuOrder,
+	}
+	if len(p.Images) > 0 {
+		image := p.Images[0]
+		v.Image = &image
+	}
+	return v
+}
+
+func variationToProduct(v ProductVariation) Product {
+	p := Product{
+		ID:               v.ID,
+		Description:      v.Description,
+		SKU:              v.SKU,
+		RegularPrice:     v.RegularPrice,
+		SalePrice:        v.SalePrice,
+		DateOnSaleFrom:   v.DateOnSaleFrom,
+		DateOnSaleTo:     v.DateOnSaleTo,
+		Status:           v.Status,
+		Virtual:          v.Virtual,
+		Downloadable:     v.Downloadable,
+		Downloads:        v.Downloads,
+		DownloadLimit:    v.DownloadLimit,
+		DownloadExpiry:   v.DownloadExpiry,
+		TaxStatus:        v.TaxStatus,
+		TaxClass:         v.TaxClass,
+		ManageStock:      v.ManageStock,
+		StockQuantity:    v.StockQuantity,
+		StockStatus:      v.StockStatus,
+		Backorders:       v.Backorders,
+		LowStockAmount:   v.LowStockAmount,
+		SoldIndividually: v.SoldIndividually,
+		Weight:           v.Weight,
+		Dimensions:       v.Dimensions,
+		ShippingClass:    v.ShippingClass,
+		Attributes:       v.Attributes,
+		MetaData:         v.MetaData,
+		MenuOrder:        v.MenuOrder,
+	}
+	if v.Image != nil {
+		p.Images = []ProductImage{*v.Image}
+	}
+	return p
+}
+
+func idRefs(ids []int64) []string {
+	refs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		refs = append(refs, fmt.Sprintf("id:%d", id))
+	}
+	return refs
+}
+
+// splitCSVList splits a WooCommerce list cell on commas, honouring "\," escapes
+func splitCSVList(value string) []string {
+	var items []string
+	var current strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == ',':
+			current.WriteByte(',')
+			i++
+		case value[i] == ',':
+			if item := strings.TrimSpace(current.String()); item != "" {
+				items = append(items, item)
+			}
+			current.Reset()
+		default:
+			current.WriteByte(value[i])
+		}
+	}
+	if item := strings.TrimSpace(current.String()); item != "" {
+		items = append(items, item)
+	}
+	return items
+}
+
+// joinCSVList is the inverse of splitCSVList
+func joinCSVList(items []string) string {
+	escaped := make([]string, 0, len(items))
+	for _, item := range items {
+		escaped = append(escaped, strings.ReplaceAll(item, ",", `\,`))
+	}
+	return strings.Join(escaped, ", ")
+}
+
+func csvBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "yes", "true":
+		return true
+	}
+	return false
+}
+
+func csvBoolString(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+func csvInt64(value string) (*int64, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func csvInt(value int64) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+// csvMetaValue formats scalar meta values, WooCommerce skips the others too
+func csvMetaValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64, int, int64, bool:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+func sortedKeys[T any](m map[int]T) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,299 @@
+package woocommerce
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testProductCSV = `ID,Type,SKU,Name,Published,Is featured?,Visibility in catalog,Short description,Description,Date sale price starts,Date sale price ends,Tax status,Tax class,In stock?,Stock,Low stock amount,Backorders allowed?,Sold individually?,Weight (lbs),Length (in),Width (in),Height (in),Allow customer reviews?,Purchase note,Sale price,Regular price,Categories,Tags,Shipping class,Images,Download limit,Download expiry days,Parent,Grouped products,Upsells,Cross-sells,External URL,Button text,Position,Attribute 1 name,Attribute 1 value(s),Attribute 1 visible,Attribute 1 global,Attribute 1 default,Meta: _hidden,Meta: origin
+,variable,TSHIRT,T-Shirt,1,0,visible,Short,"Long, with comma",,,taxable,,1,,,0,0,0.5,10,8,1,1,,,,"Apparel > Men, Sale","Cotton\, organic",,https://example.com/a.jpg,,,,,,,,,0,Color,"Red, Blue",1,1,Blue,x,Portugal
+,"variation, virtual",TSHIRT-RED,,1,,,,,2024-01-01 00:00:00,,taxable,,1,7,2,notify,,,,,,,,9.00,12.00,,,,,,,TSHIRT,,,,,,1,Color,Red,,1,,,
+`
+
+func TestProductCSV_ReadRows(t *testing.T) {
+	rows, err := NewProductCSV(nil).ReadRows(strings.NewReader(testProductCSV))
+	if err != nil {
+		t.Fatalf("ReadRows() err = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("ReadRows() returned %d rows", len(rows))
+	}
+
+	parent := rows[0]
+	if parent.Product.Type != ProductTypeVariable || parent.Product.Status != ProductStatusPublish || parent.Product.Weight != "0.5" {
+		t.Errorf("parent = %+v", parent.Product)
+	}
+	if !reflect.DeepEqual(parent.Categories, []string{"Apparel > Men", "Sale"}) || !reflect.DeepEqual(parent.Tags, []string{"Cotton, organic"}) {
+		t.Errorf("parent categories = %q, tags = %q", parent.Categories, parent.Tags)
+	}
+	attribute := parent.Product.Attributes[0]
+	if attribute.ID != -1 || !attribute.Variation || !reflect.DeepEqual(attribute.Options, []string{"Red", "Blue"}) {
+		t.Errorf("parent attribute = %+v", attribute)
+	}
+	if len(parent.Product.DefaultAttributes) != 1 || parent.Product.DefaultAttributes[0].Option != "Blue" {
+		t.Errorf("parent default attributes = %+v", parent.Product.DefaultAttributes)
+	}
+	if len(parent.Product.MetaData) != 2 || parent.Product.MetaData[1].Key != "origin" {
+		t.Errorf("parent meta = %+v", parent.Product.MetaData)
+	}
+
+	variation := rows[1]
+	if !variation.Variation || !variation.Product.Virtual || variation.Parent != "TSHIRT" || variation.Line != 3 {
+		t.Errorf("variation row = %+v", variation)
+	}
+	if !variation.Product.ManageStock || *variation.Product.StockQuantity != 7 || variation.Product.Backorders != BackorderPolicyNotify {
+		t.Errorf("variation stock = %+v", variation.Product)
+	}
+	if variation.Product.DateOnSaleFrom != "2024-01-01T00:00:00" || variation.Product.Attributes[0].Option != "Red" {
+		t.Errorf("variation = %+v", variation.Product)
+	}
+}
+
+func TestProductCSV_RoundTrip(t *testing.T) {
+	csvFile := NewProductCSV(nil)
+	rows, err := csvFile.ReadRows(strings.NewReader(testProductCSV))
+	if err != nil {
+		t.Fatalf("ReadRows() err = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := csvFile.WriteRows(&buf, rows); err != nil {
+		t.Fatalf("WriteRows() err = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "ID,Type,SKU,") || !strings.Contains(buf.String(), "Weight (lbs),Length (in)") {
+		t.Errorf("header = %q", strings.SplitN(buf.String(), "\n", 2)[0])
+	}
+	if strings.Contains(buf.String(), "Meta: _hidden") {
+		t.Error("private meta keys should not be exported")
+	}
+
+	again, err := csvFile.ReadRows(&buf)
+	if err != nil {
+		t.Fatalf("ReadRows() of written rows err = %v", err)
+	}
+	for i := range rows {
+		rows[i].Product.MetaData = filterMeta(rows[i].Product.MetaData)
+		again[i].Line = rows[i].Line
+		if !reflect.DeepEqual(rows[i], again[i]) {
+			t.Errorf("row %d changed:\n got %+v\nwant %+v", i, again[i], rows[i])
+		}
+	}
+}
+
+func TestProductCSV_ReadRowsUnits(t *testing.T) {
+	csvFile := NewProductCSV(nil)
+	csvFile.WeightUnit = "kg"
+	if _, err := csvFile.ReadRows(strings.NewReader(testProductCSV)); err == nil || !strings.Contains(err.Error(), "Weight (lbs)") {
+		t.Errorf("ReadRows() of a file in lbs err = %v, want a unit mismatch", err)
+	}
+
+	csvFile = NewProductCSV(nil)
+	if _, err := csvFile.ReadRows(strings.NewReader(testProductCSV)); err != nil {
+		t.Fatalf("ReadRows() err = %v", err)
+	}
+	if csvFile.WeightUnit != "lbs" || csvFile.DimensionUnit != "in" {
+		t.Errorf("units = %q, %q, want the file's", csvFile.WeightUnit, csvFile.DimensionUnit)
+	}
+}
+
+// handleUnitSettings serves the store's weight and dimension unit settings
+func handleUnitSettings(mux *http.ServeMux, weight, dimension string) {
+	for id, value := range map[string]string{"woocommerce_weight_unit": weight, "woocommerce_dimension_unit": dimension} {
+		value := value
+		mux.HandleFunc("/wp-json/wc/v3/settings/products/"+id, func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]string{"value": value})
+		})
+	}
+}
+
+func filterMeta(meta []MetaData) []MetaData {
+	var kept []MetaData
+	for _, m := range meta {
+		if !strings.HasPrefix(m.Key, "_") {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+func TestProductCSV_Import(t *testing.T) {
+	var createdProducts []Product
+	var createdVariations []ProductVariation
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products/categories", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode([]ProductCategory{{ID: 1, Name: "Apparel"}, {ID: 2, Name: "Men", ParentID: 1}})
+			return
+		}
+		var category ProductCategory
+		json.NewDecoder(r.Body).Decode(&category)
+		category.ID = 30
+		json.NewEncoder(w).Encode(category)
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/tags", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode([]ProductTag{})
+			return
+		}
+		var tag ProductTag
+		json.NewDecoder(r.Body).Decode(&tag)
+		tag.ID = 40
+		json.NewEncoder(w).Encode(tag)
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/attributes", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]ProductAttributeData{{ID: 5, Name: "Color"}})
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode([]Product{})
+			return
+		}
+		var product Product
+		json.NewDecoder(r.Body).Decode(&product)
+		product.ID = 100
+		createdProducts = append(createdProducts, product)
+		json.NewEncoder(w).Encode(product)
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/100/variations", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode([]ProductVariation{})
+			return
+		}
+		var variation ProductVariation
+		json.NewDecoder(r.Body).Decode(&variation)
+		variation.ID = 101
+		createdVariations = append(createdVariations, variation)
+		json.NewEncoder(w).Encode(variation)
+	})
+
+	handleUnitSettings(mux, "lbs", "in")
+	c := newTestClient(t, mux)
+
+	result, err := NewProductCSV(c).Import(strings.NewReader(testProductCSV))
+	if err != nil {
+		t.Fatalf("Import() err = %v", err)
+	}
+	if len(result.Errors) != 0 || result.Created != 2 {
+		t.Fatalf("Import() = %+v", result)
+	}
+	product := createdProducts[0]
+	if !reflect.DeepEqual(product.Categories, []ProductCategoryRef{{ID: 2}, {ID: 30}}) || !reflect.DeepEqual(product.Tags, []ProductTagRef{{ID: 40}}) {
+		t.Errorf("created product categories = %+v, tags = %+v", product.Categories, product.Tags)
+	}
+	if product.Attributes[0].ID != 5 || product.DefaultAttributes[0].ID != 5 {
+		t.Errorf("global attribute not resolved: %+v", product.Attributes)
+	}
+	if len(createdVariations) != 1 || createdVariations[0].SKU != "TSHIRT-RED" || createdVariations[0].Attributes[0].ID != 5 {
+		t.Errorf("created variations = %+v", createdVariations)
+	}
+
+	csvFile := NewProductCSV(c)
+	csvFile.WeightUnit = "kg"
+	if _, err := csvFile.Import(strings.NewReader(testProductCSV)); err == nil {
+		t.Error("Import() accepted a file in lbs for a store in kg")
+	}
+}
+
+func TestProductCSV_ImportFailingRow(t *testing.T) {
+	mux := http.NewServeMux()
+	for _, path := range []string{"categories", "tags", "attributes"} {
+		mux.HandleFunc("/wp-json/wc/v3/products/"+path, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[]`))
+		})
+	}
+	mux.HandleFunc("/wp-json/wc/v3/products", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode([]Product{})
+			return
+		}
+		var product Product
+		json.NewDecoder(r.Body).Decode(&product)
+		if product.SKU == "BAD" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"rest_invalid_param","message":"Invalid parameter(s): regular_price"}`))
+			return
+		}
+		product.ID = 100
+		json.NewEncoder(w).Encode(product)
+	})
+	handleUnitSettings(mux, "kg", "cm")
+	csv := "Type,SKU,Name,Regular price\nsimple,GOOD,Good,1.00\nsimple,BAD,Bad,oops\n"
+
+	result, err := NewProductCSV(newTestClient(t, mux)).Import(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Import() err = %v", err)
+	}
+	if result.Created != 1 || result.Updated != 0 || len(result.Products) != 1 {
+		t.Errorf("Import() created %d, updated %d", result.Created, result.Updated)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].SKU != "BAD" || result.Errors[0].Line != 3 {
+		t.Errorf("Import() errors = %+v", result.Errors)
+	}
+}
+
+func TestProductCSV_ImportReusesImages(t *testing.T) {
+	var updated Product
+	mux := http.NewServeMux()
+	for _, path := range []string{"categories", "tags", "attributes"} {
+		mux.HandleFunc("/wp-json/wc/v3/products/"+path, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[]`))
+		})
+	}
+	handleUnitSettings(mux, "kg", "cm")
+	existing := Product{ID: 100, SKU: "TEE", Images: []ProductImage{{ID: 7, Src: "https://example.com/a.jpg"}}}
+	mux.HandleFunc("/wp-json/wc/v3/products", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]Product{existing})
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/100", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			json.NewDecoder(r.Body).Decode(&updated)
+		}
+		json.NewEncoder(w).Encode(existing)
+	})
+	csv := "Type,SKU,Name,Images\nsimple,TEE,Tee,\"https://example.com/a.jpg, https://example.com/b.jpg\"\n"
+
+	result, err := NewProductCSV(newTestClient(t, mux)).Import(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Import() err = %v", err)
+	}
+	if result.Updated != 1 || len(result.Errors) != 0 {
+		t.Fatalf("Import() = %+v", result)
+	}
+	want := []ProductImage{{ID: 7}, {Src: "https://example.com/b.jpg"}}
+	if !reflect.DeepEqual(updated.Images, want) {
+		t.Errorf("updated images = %+v, want %+v", updated.Images, want)
+	}
+}
+
+func TestProductCSV_ImportClearsFlags(t *testing.T) {
+	var body map[string]interface{}
+	mux := http.NewServeMux()
+	for _, path := range []string{"categories", "tags", "attributes"} {
+		mux.HandleFunc("/wp-json/wc/v3/products/"+path, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[]`))
+		})
+	}
+	handleUnitSettings(mux, "kg", "cm")
+	existing := Product{ID: 100, SKU: "TEE", Featured: true, SoldIndividually: true, ReviewsAllowed: true}
+	mux.HandleFunc("/wp-json/wc/v3/products", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]Product{existing})
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/100", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(existing)
+	})
+	csv := "Type,SKU,Name,Is featured?,Sold individually?,Allow customer reviews?\nsimple,TEE,Tee,0,,0\n"
+
+	if _, err := NewProductCSV(newTestClient(t, mux)).Import(strings.NewReader(csv)); err != nil {
+		t.Fatalf("Import() err = %v", err)
+	}
+	if body["featured"] != false || body["reviews_allowed"] != false {
+		t.Errorf("update body = %v, want featured and reviews_allowed false", body)
+	}
+	if _, ok := body["sold_individually"]; ok {
+		t.Errorf("update body = %v, sold_individually was left empty", body)
+	}
+}
@@ -23,6 +23,8 @@ type ProductShippingClass struct {
 	Slug  string `json:"slug,omitempty"`
 	Count int64  `json:"count,omitempty"`
 	Links Links  `json:"_links,omitempty"`
+	// Error is set on the items of a batch response WooCommerce rejected.
+	Error *BatchItemError `json:"error,omitempty"`
 }
 
 type ProductShippingClassListOption struct {
@@ -24,6 +24,8 @@ type ProductTag struct {
 	Description string `json:"description,omitempty"`
 	Count       int64  `json:"count,omitempty"`
 	Links       Links  `json:"_links,omitempty"`
+	// Error is set on the items of a batch response WooCommerce rejected.
+	Error *BatchItemError `json:"error,omitempty"`
 }
 
 type ProductTagListOption struct {
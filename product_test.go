@@ -36,7 +36,7 @@ func TestProductServiceOp_Create(t *testing.T) {
 		ShortDescription: "Short test product description",
 		SKU:              "test-sku-" + time.Now().Format("20060102150405"),
 		ManageStock:      true,
-		StockQuantity:    "100",
+		StockQuantity:    Int64(100),
 		Status:           "publish",
 	}
 	res, err := client.Product.Create(product)
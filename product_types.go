@@ -0,0 +1,158 @@
+package woocommerce
+
+import (
+	"fmt"
+)
+
+// ProductValidationError reports a product field combination that WooCommerce
+// would refuse or silently drop for the product's type.
+type ProductValidationError struct {
+	Type    ProductType
+	Field   string
+	Message string
+}
+
+func (e ProductValidationError) Error() string {
+	return fmt.Sprintf("%s product: %s: %s", e.Type, e.Field, e.Message)
+}
+
+// NewSimpleProduct returns a simple product sold at regularPrice
+func NewSimpleProduct(name, regularPrice string) Product {
+	return Product{
+		Name:         name,
+		Type:         ProductTypeSimple,
+		RegularPrice: regularPrice,
+	}
+}
+
+// NewVariableProduct returns a variable product. At least one of attributes should
+// have Variation set so that variations can be created against it.
+func NewVariableProduct(name string, attributes []ProductAttribute) Product {
+	return Product{
+		Name:       name,
+		Type:       ProductTypeVariable,
+		Attributes: attributes,
+	}
+}
+
+// NewGroupedProduct returns a grouped product bundling the given child product IDs
+func NewGroupedProduct(name string, children []int64) Product {
+	return Product{
+		Name:            name,
+		Type:            ProductTypeGrouped,
+		GroupedProducts: children,
+	}
+}
+
+// NewExternalProduct returns an external (affiliate) product that links to externalUrl
+func NewExternalProduct(name, externalUrl, buttonText, regularPrice string) Product {
+	return Product{
+		Name:         name,
+		Type:         ProductTypeExternal,
+		ExternalUrl:  externalUrl,
+		ButtonText:   buttonText,
+		RegularPrice: regularPrice,
+	}
+}
+
+// NewDownloadableProduct returns a virtual, downloadable simple product
+func NewDownloadableProduct(name, regularPrice string, downloads []ProductDownload) Product {
+	return Product{
+		Name:         name,
+		Type:         ProductTypeSimple,
+		RegularPrice: regularPrice,
+		Virtual:      true,
+		Downloadable: true,
+		Downloads:    downloads,
+	}
+}
+
+// Validate checks the product for field combinations WooCommerce rejects for its
+// type, returning a ProductValidationError describing the first one found.
+// An empty Type is treated as "simple", which is the API's default.
+func (p *Product) Validate() error {
+	productType := p.Type
+	if productType == "" {
+		productType = ProductTypeSimple
+	}
+	invalid := func(field, message string) error {
+		return ProductValidationError{Type: productType, Field: field, Message: message}
+	}
+
+	if !productType.Valid() {
+		return invalid("type", "unknown product type")
+	}
+
+	if productType != ProductTypeGrouped && len(p.GroupedProducts) > 0 {
+		return invalid("grouped_products", "only grouped products can have children")
+	}
+	if productType != ProductTypeExternal && (p.ExternalUrl != "" || p.ButtonText != "") {
+		return invalid("external_url", "only external products link to an external url")
+	}
+	if p.Downloadable && productType != ProductTypeSimple {
+		return invalid("downloadable", "only simple products (and variations) can be downloadable")
+	}
+	if len(p.Downloads) > 0 && !p.Downloadable {
+		return invalid("downloads", "downloads require downloadable to be set")
+	}
+	for _, download := range p.Downloads {
+		if download.File == "" {
+			return invalid("downloads", "download file url is required")
+		}
+	}
+	if p.DownloadLimit < -1 || p.DownloadExpiry < -1 {
+		return invalid("download_limit", "download limit and expiry must be -1 (unlimited) or positive")
+	}
+
+	switch productType {
+	case ProductTypeExternal:
+		if p.ExternalUrl == "" {
+			return invalid("external_url", "external url is required")
+		}
+		if p.ManageStock {
+			return invalid("manage_stock", "external products cannot be stock managed")
+		}
+		if p.Backorders != "" && p.Backorders != BackorderPolicyNo {
+			return invalid("backorders", "external products cannot be backordered")
+		}
+		if p.StockStatus != "" && p.StockStatus != StockStatusInStock {
+			return invalid("stock_status", "external products are always in stock")
+		}
+		if p.Virtual {
+			return invalid("virtual", "external products cannot be virtual")
+		}
+	case ProductTypeGrouped:
+		if p.RegularPrice != "" || p.SalePrice != "" {
+			return invalid("regular_price", "grouped products take their price from their children")
+		}
+		if p.ManageStock {
+			return invalid("manage_stock", "grouped products cannot be stock managed")
+		}
+		for _, id := range p.GroupedProducts {
+			if id == p.ID && id != 0 {
+				return invalid("grouped_products", "a grouped product cannot contain itself")
+			}
+		}
+	case ProductTypeVariable:
+		if p.RegularPrice != "" || p.SalePrice != "" {
+			return invalid("regular_price", "variable products take their price from their variations")
+		}
+		hasVariationAttribute := false
+		for _, attribute := range p.Attributes {
+			if attribute.Variation {
+				hasVariationAttribute = true
+				break
+			}
+		}
+		if !hasVariationAttribute {
+			return invalid("attributes", "variable products need at least one attribute used for variations")
+		}
+	}
+
+	return nil
+}
+
+// Int64 returns a pointer to v, for optional numeric fields such as Product.StockQuantity
+func Int64(v int64) *int64 {
+	return &v
+}
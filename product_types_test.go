@@ -0,0 +1,140 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const productResponseJSON = `{
+	"id": 799,
+	"name": "Ship Your Idea",
+	"type": "variable",
+	"on_sale": true,
+	"purchasable": true,
+	"virtual": false,
+	"downloadable": false,
+	"download_limit": -1,
+	"download_expiry": -1,
+	"external_url": "",
+	"button_text": "",
+	"backorders_allowed": false,
+	"manage_stock": true,
+	"stock_quantity": 5,
+	"low_stock_amount": null,
+	"reviews_allowed": true,
+	"date_on_sale_from_gmt": "2017-03-23T00:00:00",
+	"categories": [{"id": 9, "name": "Clothing", "slug": "clothing"}],
+	"tags": [{"id": 34, "name": "Leather Shoes", "slug": "leather-shoes"}],
+	"attributes": [{"id": 6, "name": "Color", "position": 0, "visible": false, "variation": true, "options": ["Black", "Green"]}],
+	"default_attributes": [{"id": 6, "name": "Color", "option": "black"}],
+	"variations": [800, 801],
+	"grouped_products": []
+}`
+
+func TestProduct_UnmarshalResponse(t *testing.T) {
+	var product Product
+	if err := json.Unmarshal([]byte(productResponseJSON), &product); err != nil {
+		t.Fatalf("decode product: %v", err)
+	}
+	if len(product.Categories) != 1 || product.Categories[0].Slug != "clothing" {
+		t.Errorf("categories = %+v", product.Categories)
+	}
+	if len(product.Tags) != 1 || product.Tags[0].ID != 34 {
+		t.Errorf("tags = %+v", product.Tags)
+	}
+	if len(product.Variations) != 2 || !product.OnSale || !product.Purchasable || !product.ReviewsAllowed {
+		t.Errorf("unexpected product flags: %+v", product)
+	}
+	if product.DownloadLimit != -1 || product.DateOnSaleFromGmt != "2017-03-23T00:00:00" {
+		t.Errorf("unexpected download/sale fields: %+v", product)
+	}
+	if !product.ManageStock || product.StockQuantity == nil || *product.StockQuantity != 5 || product.LowStockAmount != nil {
+		t.Errorf("stock fields = %v, %v", product.StockQuantity, product.LowStockAmount)
+	}
+	if len(product.Attributes) != 1 || len(product.Attributes[0].Options) != 2 {
+		t.Errorf("attributes = %+v", product.Attributes)
+	}
+	if len(product.DefaultAttributes) != 1 || product.DefaultAttributes[0].Option != "black" {
+		t.Errorf("default attributes = %+v", product.DefaultAttributes)
+	}
+
+	b, err := json.Marshal(product)
+	if err != nil {
+		t.Fatalf("encode product: %v", err)
+	}
+	var roundTrip Product
+	if err := json.Unmarshal(b, &roundTrip); err != nil {
+		t.Fatalf("decode round trip: %v", err)
+	}
+	if roundTrip.Categories[0] != product.Categories[0] || roundTrip.Variations[1] != 801 || *roundTrip.StockQuantity != 5 {
+		t.Errorf("round trip lost data: %+v", roundTrip)
+	}
+}
+
+func TestProduct_Validate(t *testing.T) {
+	colors := []ProductAttribute{{Name: "Color", Variation: true, Options: []string{"Red", "Blue"}}}
+	tests := []struct {
+		name    string
+		product Product
+		field   string
+	}{
+		{"simple", NewSimpleProduct("Mug", "9.99"), ""},
+		{"variable", NewVariableProduct("Shirt", colors), ""},
+		{"grouped", NewGroupedProduct("Set", []int64{1, 2}), ""},
+		{"external", NewExternalProduct("Book", "https://example.com", "Buy", "20"), ""},
+		{"downloadable", NewDownloadableProduct("Album", "5", []ProductDownload{{Name: "mp3", File: "https://example.com/a.zip"}}), ""},
+		{"unknown type", Product{Type: "bundle"}, "type"},
+		{"external without url", NewExternalProduct("Book", "", "Buy", "20"), "external_url"},
+		{"external managed stock", func() Product {
+			p := NewExternalProduct("Book", "https://example.com", "Buy", "20")
+			p.ManageStock = true
+			return p
+		}(), "manage_stock"},
+		{"grouped with price", func() Product {
+			p := NewGroupedProduct("Set", []int64{1})
+			p.RegularPrice = "10"
+			return p
+		}(), "regular_price"},
+		{"simple with children", Product{Type: "simple", GroupedProducts: []int64{3}}, "grouped_products"},
+		{"variable without variation attribute", NewVariableProduct("Shirt", nil), "attributes"},
+		{"downloads without flag", Product{Downloads: []ProductDownload{{File: "https://example.com/a.zip"}}}, "downloads"},
+		{"downloadable variable", Product{Type: "variable", Downloadable: true, Attributes: colors}, "downloadable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.product.Validate()
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr ProductValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() = %v, want ProductValidationError", err)
+			}
+			if validationErr.Field != tt.field {
+				t.Errorf("Validate() field = %s, want %s", validationErr.Field, tt.field)
+			}
+		})
+	}
+}
+
+func TestProductEnums_Valid(t *testing.T) {
+	if !ProductTypeVariable.Valid() || ProductType("bundle").Valid() {
+		t.Errorf("unexpected ProductType validity")
+	}
+	if !StockStatusOnBackorder.Valid() || StockStatus("in_stock").Valid() {
+		t.Errorf("unexpected StockStatus validity")
+	}
+	if !CatalogVisibilityHidden.Valid() || CatalogVisibility("none").Valid() {
+		t.Errorf("unexpected CatalogVisibility validity")
+	}
+	if !DiscountTypeFixedCart.Valid() || DiscountType("fixed").Valid() {
+		t.Errorf("unexpected DiscountType validity")
+	}
+	if !CategoryDisplayBoth.Valid() || CategoryDisplay("all").Valid() {
+		t.Errorf("unexpected CategoryDisplay validity")
+	}
+}
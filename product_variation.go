@@ -16,46 +16,66 @@ type ProductVariationService interface {
 	Update(productID int64, variation *ProductVariation) (*ProductVariation, error)
 	Delete(productID int64, variationID int64, options interface{}) (*ProductVariation, error)
 	Batch(productID int64, data ProductVariationBatchOption) (*ProductVariationBatchResource, error)
+	GetBySKU(productID int64, sku string) (*ProductVariation, error)
 }
 
+// ProductVariation represent a variation of a variable product
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#product-variation-properties
 type ProductVariation struct {
-	ID               int64              `json:"id,omitempty"`
-	DateCreated      string             `json:"date_created,omitempty"`
-	DateCreatedGmt   string             `json:"date_created_gmt,omitempty"`
-	DateModified     string             `json:"date_modified,omitempty"`
-	DateModifiedGmt  string             `json:"date_modified_gmt,omitempty"`
-	Permalink        string             `json:"permalink,omitempty"`
-	SKU              string             `json:"sku,omitempty"`
-	Price            string             `json:"price,omitempty"`
-	RegularPrice     string             `json:"regular_price,omitempty"`
-	SalePrice        string             `json:"sale_price,omitempty"`
-	DateOnSaleFrom   string             `json:"date_on_sale_from,omitempty"`
-	DateOnSaleTo     string             `json:"date_on_sale_to,omitempty"`
-	Status           string             `json:"status,omitempty"`
-	Virtual          bool               `json:"virtual,omitempty"`
-	Downloadable     bool               `json:"downloadable,omitempty"`
-	ManageStock      bool               `json:"manage_stock,omitempty"`
-	StockQuantity    string             `json:"stock_quantity,omitempty"`
-	StockStatus      string             `json:"stock_status,omitempty"`
-	Backorders       string             `json:"backorders,omitempty"`
-	LowStockAmount   string             `json:"low_stock_amount,omitempty"`
-	SoldIndividually bool               `json:"sold_individually,omitempty"`
-	Weight           string             `json:"weight,omitempty"`
-	Length           string             `json:"length,omitempty"`
-	Width            string             `json:"width,omitempty"`
-	Height           string             `json:"height,omitempty"`
-	Dimensions       map[string]string  `json:"dimensions,omitempty"`
-	ShippingClass    string             `json:"shipping_class,omitempty"`
-	ShippingClassID  int64              `json:"shipping_class_id,omitempty"`
-	Image            ProductImage       `json:"image,omitempty"`
-	Attributes       []ProductAttribute `json:"attributes,omitempty"`
-	MetaData         []MetaData         `json:"meta_data,omitempty"`
-	MenuOrder        int                `json:"menu_order,omitempty"`
-	Links            Links              `json:"_links,omitempty"`
+	ID                int64              `json:"id,omitempty"`
+	DateCreated       string             `json:"date_created,omitempty"`
+	DateCreatedGmt    string             `json:"date_created_gmt,omitempty"`
+	DateModified      string             `json:"date_modified,omitempty"`
+	DateModifiedGmt   string             `json:"date_modified_gmt,omitempty"`
+	Description       string             `json:"description,omitempty"`
+	Permalink         string             `json:"permalink,omitempty"`
+	SKU               string             `json:"sku,omitempty"`
+	Price             string             `json:"price,omitempty"`
+	RegularPrice      string             `json:"regular_price,omitempty"`
+	SalePrice         string             `json:"sale_price,omitempty"`
+	DateOnSaleFrom    string             `json:"date_on_sale_from,omitempty"`
+	DateOnSaleFromGmt string             `json:"date_on_sale_from_gmt,omitempty"`
+	DateOnSaleTo      string             `json:"date_on_sale_to,omitempty"`
+	DateOnSaleToGmt   string             `json:"date_on_sale_to_gmt,omitempty"`
+	OnSale            bool               `json:"on_sale,omitempty"`
+	Status            ProductStatus      `json:"status,omitempty"`
+	Purchasable       bool               `json:"purchasable,omitempty"`
+	Virtual           bool               `json:"virtual,omitempty"`
+	Downloadable      bool               `json:"downloadable,omitempty"`
+	Downloads         []ProductDownload  `json:"downloads,omitempty"`
+	DownloadLimit     int64              `json:"download_limit,omitempty"`
+	DownloadExpiry    int64              `json:"download_expiry,omitempty"`
+	TaxStatus         string             `json:"tax_status,omitempty"`
+	TaxClass          string             `json:"tax_class,omitempty"`
+	ManageStock       bool               `json:"manage_stock,omitempty"`
+	StockQuantity     *int64             `json:"stock_quantity,omitempty"`
+	StockStatus       StockStatus        `json:"stock_status,omitempty"`
+	Backorders        BackorderPolicy    `json:"backorders,omitempty"`
+	BackordersAllowed bool               `json:"backorders_allowed,omitempty"`
+	Backordered       bool               `json:"backordered,omitempty"`
+	LowStockAmount    *int64             `json:"low_stock_amount,omitempty"`
+	SoldIndividually  bool               `json:"sold_individually,omitempty"`
+	Weight            string             `json:"weight,omitempty"`
+	Length            string             `json:"length,omitempty"`
+	Width             string             `json:"width,omitempty"`
+	Height            string             `json:"height,omitempty"`
+	Dimensions        map[string]string  `json:"dimensions,omitempty"`
+	ShippingClass     string             `json:"shipping_class,omitempty"`
+	ShippingClassID   int64              `json:"shipping_class_id,omitempty"`
+	Image             *ProductImage      `json:"image,omitempty"`
+	Attributes        []ProductAttribute `json:"attributes,omitempty"`
+	MetaData          []MetaData         `json:"meta_data,omitempty"`
+	MenuOrder         int                `json:"menu_order,omitempty"`
+	Links             Links              `json:"_links,omitempty"`
+	Embedded          Embedded           `json:"_embedded,omitempty"`
+	// Error is set on the items of a batch response WooCommerce rejected.
+	Error *BatchItemError `json:"error,omitempty"`
 }
 
 type ProductVariationListOption struct {
 	ListOptions
+	// SKU filters by SKU, several SKUs may be separated by commas
+	SKU string `url:"sku,omitempty"`
 }
 
 type ProductVariationBatchOption struct {
@@ -115,3 +135,17 @@ func (p *ProductVariationServiceOp) Batch(productID int64, data ProductVariation
 	err := p.client.Post(path, data, &resource)
 	return resource, err
 }
+
+// GetBySKU returns the variation of the product whose SKU is exactly sku, or ErrSKUNotFound
+func (p *ProductVariationServiceOp) GetBySKU(productID int64, sku string) (*ProductVariation, error) {
+	variations, err := p.List(productID, ProductVariationListOption{SKU: sku})
+	if err != nil {
+		return nil, err
+	}
+	for i := range variations {
+		if variations[i].SKU == sku {
+			return &variations[i], nil
+		}
+	}
+	return nil, fmt.Errorf("product %d variation %q: %w", productID, sku, ErrSKUNotFound)
+}
@@ -0,0 +1,180 @@
+package woocommerce
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VariationTemplate fills in the pricing, SKU and stock of the variation for one
+// combination of attribute options. The returned variation's Attributes are
+// overwritten with combination.
+type VariationTemplate func(product *Product, combination []ProductAttribute) ProductVariation
+
+// VariationMatrix keeps the variations of a variable product in line with the
+// cartesian product of its variation-enabled attribute options.
+type VariationMatrix struct {
+	variations ProductVariationService
+	// Template, when set, shapes each variation created for a missing combination.
+	Template VariationTemplate
+	// UpdateExisting re-applies Template to variations that already exist.
+	UpdateExisting bool
+	// DeleteOrphans removes variations whose attributes match no combination.
+	DeleteOrphans bool
+}
+
+// NewVariationMatrix returns a VariationMatrix using the client's ProductVariationService
+func NewVariationMatrix(c *Client, template VariationTemplate) *VariationMatrix {
+	return &VariationMatrix{
+		variations: c.ProductVariation,
+		Template:   template,
+	}
+}
+
+// VariationCombinations returns every combination of the options of the product's
+// attributes that are used for variations. Each combination holds one attribute
+// per variation attribute with Option set.
+func VariationCombinations(product *Product) [][]ProductAttribute {
+	combinations := [][]ProductAttribute{{}}
+	found := false
+	for _, attribute := range product.Attributes {
+		if !attribute.Variation || len(attribute.Options) == 0 {
+			continue
+		}
+		found = true
+		next := make([][]ProductAttribute, 0, len(combinations)*len(attribute.Options))
+		for _, combination := range combinations {
+			for _, option := range attribute.Options {
+				combined := make([]ProductAttribute, len(combination), len(combination)+1)
+				copy(combined, combination)
+				combined = append(combined, ProductAttribute{ID: attribute.ID, Name: attribute.Name, Option: option})
+				next = append(next, combined)
+			}
+		}
+		combinations = next
+	}
+	if !found {
+		return nil
+	}
+	return combinations
+}
+
+// Sync lists the product's variations, creates the ones missing from the matrix
+// and, depending on UpdateExisting and DeleteOrphans, updates and deletes the
+// others, all through ProductVariationService.Batch. The merged batch results are
+// returned, with an error naming the variations WooCommerce rejected.
+func (m *VariationMatrix) Sync(product *Product) (*ProductVariationBatchResource, error) {
+	if product.ID == 0 {
+		return nil, fmt.Errorf("product must be created before its variations")
+	}
+	combinations := VariationCombinations(product)
+	if len(combinations) == 0 {
+		return nil, fmt.Errorf("product %d has no attribute used for variations", product.ID)
+	}
+
+	existing, err := m.listAll(product.ID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]ProductVariation, len(existing))
+	for _, variation := range existing {
+		byKey[combinationKey(variation.Attributes)] = variation
+	}
+
+	batch := ProductVariationBatchOption{}
+	wanted := make(map[string]bool, len(combinations))
+	for _, combination := range combinations {
+		key := combinationKey(combination)
+		wanted[key] = true
+		current, exists := byKey[key]
+		if exists && !m.UpdateExisting {
+			continue
+		}
+		variation := ProductVariation{}
+		if m.Template != nil {
+			variation = m.Template(product, combination)
+		}
+		variation.Attributes = combination
+		if exists {
+			variation.ID = current.ID
+			batch.Update = append(batch.Update, variation)
+		} else {
+			variation.ID = 0
+			batch.Create = append(batch.Create, variation)
+		}
+	}
+	if m.DeleteOrphans {
+		for key, variation := range byKey {
+			if !wanted[key] {
+				batch.Delete = append(batch.Delete, variation.ID)
+			}
+		}
+		sort.Slice(batch.Delete, func(i, j int) bool { return batch.Delete[i] < batch.Delete[j] })
+	}
+
+	return m.batch(product.ID, batch)
+}
+
+func (m *VariationMatrix) listAll(productID int64) ([]ProductVariation, error) {
+	var all []ProductVariation
+	for page := 1; ; page++ {
+		options := ProductVariationListOption{ListOptions: ListOptions{Page: page, PerPage: maxBatchSize}}
+		variations, err := m.variations.List(productID, options)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, variations...)
+		if len(variations) < maxBatchSize {
+			return all, nil
+		}
+	}
+}
+
+// batch sends data in chunks WooCommerce accepts and merges the results, joining
+// the errors of the items WooCommerce rejected
+func (m *VariationMatrix) batch(productID int64, data ProductVariationBatchOption) (*ProductVariationBatchResource, error) {
+	result := &ProductVariationBatchResource{}
+	var errs []error
+	err := batchChunks(data.Create, data.Update, data.Delete, func(create, update []ProductVariation, ids []int64) error {
+		resource, err := m.variations.Batch(productID, ProductVariationBatchOption{Create: create, Update: update, Delete: ids})
+		if err != nil {
+			return err
+		}
+		result.Create = append(result.Create, resource.Create...)
+		result.Update = append(result.Update, resource.Update...)
+		result.Delete = append(result.Delete, resource.Delete...)
+		errs = append(errs,
+			batchItemsError("variation", create, resource.Create, variationKey, variationError),
+			batchItemsError("variation", update, resource.Update, variationKey, variationError),
+			batchDeletesError("variation", ids, resource.Delete, variationError))
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, errors.Join(errs...)
+}
+
+// variationKey names a variation in errors by its SKU, or its attributes
+func variationKey(v ProductVariation) string {
+	if v.SKU != "" {
+		return v.SKU
+	}
+	return combinationKey(v.Attributes)
+}
+
+// combinationKey identifies a set of attribute options regardless of order and case.
+// Global attributes are matched by ID, custom (local) attributes by name.
+func combinationKey(attributes []ProductAttribute) string {
+	parts := make([]string, 0, len(attributes))
+	for _, attribute := range attributes {
+		name := "name:" + strings.ToLower(attribute.Name)
+		if attribute.ID != 0 {
+			name = fmt.Sprintf("id:%d", attribute.ID)
+		}
+		parts = append(parts, name+"="+strings.ToLower(attribute.Option))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,109 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func variableShirt() *Product {
+	return &Product{
+		ID:   50,
+		Type: ProductTypeVariable,
+		Attributes: []ProductAttribute{
+			{ID: 1, Name: "Color", Variation: true, Options: []string{"Red", "Blue"}},
+			{Name: "Size", Variation: true, Options: []string{"S", "M", "L"}},
+			{Name: "Material", Options: []string{"Cotton"}},
+		},
+	}
+}
+
+func TestVariationCombinations(t *testing.T) {
+	combinations := VariationCombinations(variableShirt())
+	if len(combinations) != 6 {
+		t.Fatalf("got %d combinations, want 6", len(combinations))
+	}
+	if c := combinations[5]; c[0].Option != "Blue" || c[1].Option != "L" || len(c) != 2 {
+		t.Errorf("last combination = %+v", c)
+	}
+	if VariationCombinations(&Product{}) != nil {
+		t.Errorf("product without variation attributes should have no combinations")
+	}
+}
+
+func TestVariationMatrix_Sync(t *testing.T) {
+	var received ProductVariationBatchOption
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products/50/variations", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]ProductVariation{
+			{ID: 501, Attributes: []ProductAttribute{{ID: 1, Name: "Color", Option: "red"}, {Name: "Size", Option: "S"}}},
+			{ID: 502, Attributes: []ProductAttribute{{ID: 1, Name: "Color", Option: "Green"}, {Name: "Size", Option: "S"}}},
+		})
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/50/variations/batch", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		resource := ProductVariationBatchResource{}
+		for i := range received.Create {
+			resource.Create = append(resource.Create, &received.Create[i])
+		}
+		json.NewEncoder(w).Encode(resource)
+	})
+
+	matrix := NewVariationMatrix(newTestClient(t, mux), func(product *Product, combination []ProductAttribute) ProductVariation {
+		price := "20.00"
+		if combination[1].Option == "L" {
+			price = "22.00"
+		}
+		return ProductVariation{SKU: "SHIRT-" + combination[0].Option + "-" + combination[1].Option, RegularPrice: price}
+	})
+	matrix.DeleteOrphans = true
+
+	result, err := matrix.Sync(variableShirt())
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if len(result.Create) != 5 || len(received.Update) != 0 {
+		t.Fatalf("created %d, updated %d variations", len(result.Create), len(received.Update))
+	}
+	if len(received.Delete) != 1 || received.Delete[0] != 502 {
+		t.Errorf("deleted %v, want [502]", received.Delete)
+	}
+	last := received.Create[4]
+	if last.SKU != "SHIRT-Blue-L" || last.RegularPrice != "22.00" || len(last.Attributes) != 2 {
+		t.Errorf("templated variation = %+v", last)
+	}
+}
+
+func TestVariationMatrix_SyncItemError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products/50/variations", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/50/variations/batch", func(w http.ResponseWriter, r *http.Request) {
+		var received ProductVariationBatchOption
+		json.NewDecoder(r.Body).Decode(&received)
+		resource := ProductVariationBatchResource{}
+		for i := range received.Create {
+			variation := &received.Create[i]
+			if variation.SKU == "SHIRT-Blue-M" {
+				variation = &ProductVariation{Error: &BatchItemError{Code: "product_invalid_sku", Message: "Invalid or duplicated SKU."}}
+			}
+			resource.Create = append(resource.Create, variation)
+		}
+		json.NewEncoder(w).Encode(resource)
+	})
+	matrix := NewVariationMatrix(newTestClient(t, mux), func(product *Product, combination []ProductAttribute) ProductVariation {
+		return ProductVariation{SKU: "SHIRT-" + combination[0].Option + "-" + combination[1].Option}
+	})
+
+	result, err := matrix.Sync(variableShirt())
+	var itemErr *BatchItemError
+	if !errors.As(err, &itemErr) || itemErr.Code != "product_invalid_sku" || !strings.Contains(err.Error(), `"SHIRT-Blue-M"`) {
+		t.Fatalf("Sync() err = %v", err)
+	}
+	if result == nil || len(result.Create) != 6 {
+		t.Errorf("Sync() = %+v", result)
+	}
+}
@@ -23,7 +23,7 @@ func TestProductVariationServiceOp_Create(t *testing.T) {
 		RegularPrice:  "15.99",
 		SalePrice:     "12.99",
 		ManageStock:   true,
-		StockQuantity: "50",
+		StockQuantity: Int64(50),
 		Status:        "publish",
 	}
 	res, err := client.ProductVariation.Create(productID, variation)
@@ -0,0 +1,156 @@
+// Package promwoo exports Prometheus metrics about the API calls of woocommerce clients.
+//
+// One Collector can serve the clients of several stores, each call is labelled
+// with the store it went to and its endpoint, e.g. "orders/{id}".
+//
+//	collector := promwoo.NewCollector()
+//	prometheus.MustRegister(collector)
+//	client := woocommerce.NewClient(app, "shop.example.com", woocommerce.WithMiddleware(collector.Middleware("")))
+package promwoo
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/chenyangguang/woocommerce"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector collects the metrics of the calls made through its middlewares
+type Collector struct {
+	requests      *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	errors        *prometheus.CounterVec
+	retries       *prometheus.CounterVec
+	rateLimitWait *prometheus.CounterVec
+}
+
+type config struct {
+	namespace string
+	buckets   []float64
+}
+
+// Option configures a Collector
+type Option func(c *config)
+
+// WithNamespace sets the prefix of the metric names, "woocommerce" by default
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithBuckets sets the buckets of the latency histogram, in seconds
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.buckets = buckets
+	}
+}
+
+// NewCollector creates the metrics, register the collector to export them
+func NewCollector(opts ...Option) *Collector {
+	cfg := config{
+		namespace: "woocommerce",
+		buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "requests_total",
+			Help:      "API requests sent, by store, endpoint, method and response status.",
+		}, []string{"store", "endpoint", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of API requests.",
+			Buckets:   cfg.buckets,
+		}, []string{"store", "endpoint", "method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "errors_total",
+			Help:      "Failed API requests, by WooCommerce error code.",
+		}, []string{"store", "endpoint", "code"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "retries_total",
+			Help:      "API requests that were retries of a rate limited or failed request.",
+		}, []string{"store", "endpoint"}),
+		rateLimitWait: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "rate_limit_wait_seconds_total",
+			Help:      "Time spent waiting before retrying rate limited requests.",
+		}, []string{"store"}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.errors.Describe(ch)
+	c.retries.Describe(ch)
+	c.rateLimitWait.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.errors.Collect(ch)
+	c.retries.Collect(ch)
+	c.rateLimitWait.Collect(ch)
+}
+
+// Middleware returns a woocommerce.Middleware recording the calls of a client.
+// store labels its metrics, the host of the shop is used when it is empty.
+func (c *Collector) Middleware(store string) woocommerce.Middleware {
+	return func(next woocommerce.CallHandler) woocommerce.CallHandler {
+		return func(call *woocommerce.Call) (*http.Response, error) {
+			name := store
+			if name == "" {
+				name = call.Request.URL.Host
+			}
+			endpoint := call.Endpoint()
+			if call.Attempt > 1 {
+				c.retries.WithLabelValues(name, endpoint).Inc()
+			}
+			if call.RateLimitWait > 0 {
+				c.rateLimitWait.WithLabelValues(name).Add(call.RateLimitWait.Seconds())
+			}
+
+			start := time.Now()
+			resp, err := next(call)
+			c.duration.WithLabelValues(name, endpoint, call.Method).Observe(time.Since(start).Seconds())
+
+			status := "error"
+			if err == nil {
+				status = strconv.Itoa(resp.StatusCode)
+			}
+			c.requests.WithLabelValues(name, endpoint, call.Method, status).Inc()
+			if code := errorCode(resp, err); code != "" {
+				c.errors.WithLabelValues(name, endpoint, code).Inc()
+			}
+			return resp, err
+		}
+	}
+}
+
+// errorCode is the WooCommerce code of a failed call, "http_<status>" when the
+// response has none and "transport" when no response was received
+func errorCode(resp *http.Response, err error) string {
+	if err != nil {
+		return "transport"
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		return ""
+	}
+	body, _ := woocommerce.ReadResponseBody(resp)
+	if code := woocommerce.ResponseErrorCode(body); code != "" {
+		return code
+	}
+	return "http_" + strconv.Itoa(resp.StatusCode)
+}
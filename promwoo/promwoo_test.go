@@ -0,0 +1,60 @@
+package promwoo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chenyangguang/woocommerce"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	calls := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/orders/404"):
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"woocommerce_rest_shop_order_invalid_id","message":"Invalid ID."}`))
+		case calls == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"id":12}`))
+		}
+	}))
+	defer server.Close()
+
+	collector := NewCollector()
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	client := woocommerce.NewClient(woocommerce.App{CustomerKey: "ck", CustomerSecret: "cs"},
+		strings.TrimPrefix(server.URL, "https://"), woocommerce.WithRetry(3), woocommerce.WithMiddleware(collector.Middleware("eu")))
+	client.Client = server.Client()
+
+	var order woocommerce.Order
+	if err := client.Get("orders/12", &order, nil); err != nil {
+		t.Fatalf("Get() err = %v", err)
+	}
+	if err := client.Get("orders/404", &order, nil); err == nil {
+		t.Fatal("Get() of a missing order succeeded")
+	}
+
+	if n := testutil.ToFloat64(collector.requests.WithLabelValues("eu", "orders/{id}", "GET", "200")); n != 1 {
+		t.Errorf("successful requests = %v", n)
+	}
+	if n := testutil.ToFloat64(collector.retries.WithLabelValues("eu", "orders/{id}")); n != 1 {
+		t.Errorf("retries = %v", n)
+	}
+	if n := testutil.ToFloat64(collector.errors.WithLabelValues("eu", "orders/{id}", "woocommerce_rest_shop_order_invalid_id")); n != 1 {
+		t.Errorf("invalid id errors = %v", n)
+	}
+	if n := testutil.ToFloat64(collector.errors.WithLabelValues("eu", "orders/{id}", "http_503")); n != 1 {
+		t.Errorf("unavailable errors = %v", n)
+	}
+	if n := testutil.CollectAndCount(collector, "woocommerce_request_duration_seconds"); n != 1 {
+		t.Errorf("latency histograms = %d", n)
+	}
+}
@@ -0,0 +1,117 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// RedactedMask replaces redacted values in logs
+const RedactedMask = "[REDACTED]"
+
+// DefaultRedactedFields are the JSON fields and query parameters masked in logs:
+// secrets, credentials and the personal data of customers and their addresses
+var DefaultRedactedFields = []string{
+	"password",
+	"secret",
+	"consumer_key",
+	"consumer_secret",
+	"oauth_signature",
+	"token",
+	"email",
+	"billing_email",
+	"phone",
+	"first_name",
+	"last_name",
+	"company",
+	"address_1",
+	"address_2",
+	"city",
+	"state",
+	"postcode",
+	"username",
+	"customer_note",
+	"customer_ip_address",
+	"customer_user_agent",
+}
+
+// Redactor masks secrets and personal data in request URLs and JSON bodies
+// before they are logged. Fields are matched case insensitively at any depth.
+type Redactor struct {
+	fields map[string]bool
+}
+
+// NewRedactor returns a Redactor masking fields
+func NewRedactor(fields ...string) *Redactor {
+	r := &Redactor{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		r.fields[strings.ToLower(field)] = true
+	}
+	return r
+}
+
+// WithRedactedFields replaces the fields masked in logs, DefaultRedactedFields by
+// default. Call it with no fields to log bodies unchanged.
+func WithRedactedFields(fields ...string) Option {
+	return func(c *Client) {
+		c.redactor = NewRedactor(fields...)
+	}
+}
+
+// Redacts tells whether field is masked
+func (r *Redactor) Redacts(field string) bool {
+	return r != nil && r.fields[strings.ToLower(field)]
+}
+
+// URL returns u with its password and redacted query parameters masked
+func (r *Redactor) URL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	masked := *u
+	if masked.RawQuery != "" {
+		query := masked.Query()
+		for key := range query {
+			if r.Redacts(key) {
+				query[key] = []string{RedactedMask}
+			}
+		}
+		masked.RawQuery = query.Encode()
+	}
+	return masked.Redacted()
+}
+
+// Body returns a JSON body with the values of redacted fields masked. Bodies
+// that are not JSON are returned unchanged.
+func (r *Redactor) Body(body []byte) []byte {
+	if r == nil || len(r.fields) == 0 {
+		return body
+	}
+	var v interface{}
+	if json.Unmarshal(body, &v) != nil {
+		return body
+	}
+	masked, err := json.Marshal(r.value(v))
+	if err != nil {
+		return body
+	}
+	return masked
+}
+
+func (r *Redactor) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if r.Redacts(key) && value != nil && value != "" {
+				v[key] = RedactedMask
+			} else {
+				v[key] = r.value(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = r.value(value)
+		}
+	}
+	return v
+}
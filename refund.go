@@ -2,6 +2,8 @@ package woocommerce
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 )
 
 const (
@@ -20,12 +22,64 @@ type OrderRefundService interface {
 // OrderRefund represent a WooCommerce Order Refund
 // https://woocommerce.github.io/woocommerce-rest-api-docs/#order-refund-properties
 type OrderRefund struct {
-	ID             int64  `json:"id,omitempty"`
-	DateCreated    string `json:"date_created,omitempty"`
-	DateCreatedGmt string `json:"date_created_gmt,omitempty"`
-	Amount         string `json:"amount,omitempty"`
-	Reason         string `json:"reason,omitempty"`
-	RefundedBy     int64  `json:"refunded_by,omitempty"`
+	ID              int64                 `json:"id,omitempty"`
+	DateCreated     string                `json:"date_created,omitempty"`
+	DateCreatedGmt  string                `json:"date_created_gmt,omitempty"`
+	Amount          string                `json:"amount,omitempty"`
+	Reason          string                `json:"reason,omitempty"`
+	RefundedBy      int64                 `json:"refunded_by,omitempty"`
+	RefundedPayment bool                  `json:"refunded_payment,omitempty"`
+	MetaData        []MetaData            `json:"meta_data,omitempty"`
+	LineItems       []OrderRefundLineItem `json:"line_items,omitempty"`
+	// APIRefund asks the payment gateway to refund the money. WooCommerce defaults
+	// it to true when omitted, set it to Bool(false) for a manual refund.
+	APIRefund *bool `json:"api_refund,omitempty"`
+	// APIRestock returns refunded line item quantities to stock. WooCommerce
+	// defaults it to true when omitted.
+	APIRestock *bool `json:"api_restock,omitempty"`
+}
+
+// OrderRefundLineItem is an order item included in a refund. When creating a
+// refund, ID is the order item ID being refunded and RefundTotal/RefundTax carry
+// the amounts, the other fields are filled in by WooCommerce.
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#order-refund-line-items-properties
+type OrderRefundLineItem struct {
+	ID          int64            `json:"id,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	ProductID   int64            `json:"product_id,omitempty"`
+	VariationID int64            `json:"variation_id,omitempty"`
+	Quantity    int              `json:"quantity,omitempty"`
+	TaxClass    string           `json:"tax_class,omitempty"`
+	SubTotal    string           `json:"subtotal,omitempty"`
+	SubtotalTax string           `json:"subtotal_tax,omitempty"`
+	Total       string           `json:"total,omitempty"`
+	TotalTax    string           `json:"total_tax,omitempty"`
+	Taxes       []OrderRefundTax `json:"taxes,omitempty"`
+	MetaData    []MetaData       `json:"meta_data,omitempty"`
+	SKU         string           `json:"sku,omitempty"`
+	Price       float64          `json:"price,omitempty"`
+	RefundTotal float64          `json:"refund_total,omitempty"`
+	RefundTax   []OrderRefundTax `json:"refund_tax,omitempty"`
+}
+
+// OrderRefundTax is the amount of one tax rate refunded on a line item
+type OrderRefundTax struct {
+	ID          int64   `json:"id,omitempty"`
+	Total       string  `json:"total,omitempty"`
+	Subtotal    string  `json:"subtotal,omitempty"`
+	RefundTotal float64 `json:"refund_total,omitempty"`
+}
+
+// RefundItem selects an order line item to refund. A zero Quantity refunds the
+// whole quantity that was ordered.
+type RefundItem struct {
+	LineItemID int64
+	Quantity   int
+}
+
+// Bool returns a pointer to v, for optional boolean fields such as OrderRefund.APIRefund
+func Bool(v bool) *bool {
+	return &v
 }
 
 type OrderRefundServiceOp struct {
@@ -59,3 +113,99 @@ func (o *OrderRefundServiceOp) Delete(orderID int64, refundID int64, options int
 	err := o.client.Delete(path, options, &resource)
 	return resource, err
 }
+
+// NewOrderRefund builds an itemised refund for order. With no items every line,
+// shipping and fee item is refunded in full, otherwise only the selected line items
+// for the given quantities. Each item's total and taxes are prorated by quantity
+// and Amount is set to their sum. Refunds already made against the order are not
+// taken into account.
+func NewOrderRefund(order *Order, items []RefundItem, reason string) (OrderRefund, error) {
+	refund := OrderRefund{Reason: reason}
+	var amount float64
+
+	addItem := func(id int64, quantity, ordered int, total string, taxes []LineItemTax) error {
+		share := 1.0
+		if ordered > 0 {
+			share = float64(quantity) / float64(ordered)
+		}
+		itemTotal, err := parseAmount(total)
+		if err != nil {
+			return err
+		}
+		item := OrderRefundLineItem{
+			ID:          id,
+			Quantity:    quantity,
+			RefundTotal: roundAmount(itemTotal * share),
+		}
+		amount += item.RefundTotal
+		for _, tax := range taxes {
+			taxTotal, err := parseAmount(tax.Total)
+			if err != nil {
+				return err
+			}
+			refundTax := roundAmount(taxTotal * share)
+			if refundTax == 0 {
+				continue
+			}
+			item.RefundTax = append(item.RefundTax, OrderRefundTax{ID: tax.ID, RefundTotal: refundTax})
+			amount += refundTax
+		}
+		refund.LineItems = append(refund.LineItems, item)
+		return nil
+	}
+
+	if len(items) == 0 {
+		for _, line := range order.LineItems {
+			if err := addItem(line.ID, line.Quantity, line.Quantity, line.Total, line.Taxes); err != nil {
+				return refund, err
+			}
+		}
+		for _, line := range order.ShippingLines {
+			if err := addItem(line.ID, 0, 0, line.Total, line.Taxes); err != nil {
+				return refund, err
+			}
+		}
+		for _, line := range order.FeeLines {
+			if err := addItem(line.ID, 0, 0, line.Total, line.Taxes); err != nil {
+				return refund, err
+			}
+		}
+	}
+
+	for _, item := range items {
+		var line *LineItem
+		for i := range order.LineItems {
+			if order.LineItems[i].ID == item.LineItemID {
+				line = &order.LineItems[i]
+				break
+			}
+		}
+		if line == nil {
+			return refund, fmt.Errorf("order %d has no line item %d", order.ID, item.LineItemID)
+		}
+		quantity := item.Quantity
+		if quantity == 0 {
+			quantity = line.Quantity
+		}
+		if quantity < 0 || quantity > line.Quantity {
+			return refund, fmt.Errorf("cannot refund %d of line item %d, %d were ordered", quantity, line.ID, line.Quantity)
+		}
+		if err := addItem(line.ID, quantity, line.Quantity, line.Total, line.Taxes); err != nil {
+			return refund, err
+		}
+	}
+
+	refund.Amount = strconv.FormatFloat(roundAmount(amount), 'f', 2, 64)
+	return refund, nil
+}
+
+func parseAmount(amount string) (float64, error) {
+	if amount == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(amount, 64)
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -1,6 +1,8 @@
 package woocommerce
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -61,3 +63,47 @@ func TestOrderRefundServiceOp_Delete(t *testing.T) {
 		t.Logf("deleted refund: id=%d", created.ID)
 	}
 }
+
+func TestNewOrderRefund(t *testing.T) {
+	order := &Order{
+		ID: 42,
+		LineItems: []LineItem{
+			{ID: 1, Quantity: 3, Total: "30.00", Taxes: []LineItemTax{{ID: 5, Total: "6.00"}}},
+			{ID: 2, Quantity: 1, Total: "15.00"},
+		},
+		ShippingLines: []ShippingLines{{ID: 3, Total: "5.00", Taxes: []LineItemTax{{ID: 5, Total: "1.00"}}}},
+	}
+
+	partial, err := NewOrderRefund(order, []RefundItem{{LineItemID: 1, Quantity: 2}}, "damaged")
+	if err != nil {
+		t.Fatalf("partial refund: %v", err)
+	}
+	if partial.Amount != "24.00" || len(partial.LineItems) != 1 {
+		t.Fatalf("partial refund = %+v", partial)
+	}
+	item := partial.LineItems[0]
+	if item.Quantity != 2 || item.RefundTotal != 20 || len(item.RefundTax) != 1 || item.RefundTax[0].RefundTotal != 4 {
+		t.Errorf("partial refund line = %+v", item)
+	}
+
+	full, err := NewOrderRefund(order, nil, "cancelled")
+	if err != nil {
+		t.Fatalf("full refund: %v", err)
+	}
+	if full.Amount != "57.00" || len(full.LineItems) != 3 {
+		t.Errorf("full refund = %+v", full)
+	}
+
+	if _, err := NewOrderRefund(order, []RefundItem{{LineItemID: 2, Quantity: 2}}, ""); err == nil {
+		t.Errorf("refunding more than ordered should fail")
+	}
+	if _, err := NewOrderRefund(order, []RefundItem{{LineItemID: 9}}, ""); err == nil {
+		t.Errorf("refunding an unknown line item should fail")
+	}
+
+	partial.APIRefund = Bool(false)
+	b, _ := json.Marshal(partial)
+	if !strings.Contains(string(b), `"api_refund":false`) || !strings.Contains(string(b), `"refund_tax":[{"id":5,"refund_total":4}]`) {
+		t.Errorf("unexpected refund payload: %s", b)
+	}
+}
@@ -0,0 +1,157 @@
+package woocommerce
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/google/go-querystring/query"
+)
+
+// Resource is a typed client for a REST collection of items T listed with
+// options ListOpt, to wrap the endpoints of extensions with the retries,
+// logging, middlewares and errors of the built-in services:
+//
+//	type Subscription struct {
+//		ID     int64  `json:"id,omitempty"`
+//		Status string `json:"status,omitempty"`
+//	}
+//	subscriptions := woocommerce.NewResource[Subscription, woocommerce.ListOptions](client, "subscriptions")
+//	active, err := subscriptions.ListAll(ctx, woocommerce.ListOptions{Search: "gold"})
+//
+// Path is relative to the client's API prefix, or complete to reach another
+// namespace, e.g. "/wp-json/wc-bookings/v1/bookings".
+type Resource[T any, ListOpt any] struct {
+	client *Client
+	path   string
+}
+
+// ResourceBatchOption is the batch request of a Resource
+type ResourceBatchOption[T any] struct {
+	Create []T     `json:"create,omitempty"`
+	Update []T     `json:"update,omitempty"`
+	Delete []int64 `json:"delete,omitempty"`
+}
+
+// ResourceBatchResource is the batch response of a Resource
+type ResourceBatchResource[T any] struct {
+	Create []T `json:"create,omitempty"`
+	Update []T `json:"update,omitempty"`
+	Delete []T `json:"delete,omitempty"`
+}
+
+// NewResource returns a Resource for the collection at path
+func NewResource[T any, ListOpt any](c *Client, path string) *Resource[T, ListOpt] {
+	return &Resource[T, ListOpt]{client: c, path: path}
+}
+
+func (r *Resource[T, ListOpt]) itemPath(id int64) string {
+	return fmt.Sprintf("%s/%d", r.path, id)
+}
+
+// List returns a page of items
+func (r *Resource[T, ListOpt]) List(options ListOpt) ([]T, error) {
+	return r.ListWithContext(context.Background(), options)
+}
+
+// ListWithContext returns a page of items
+func (r *Resource[T, ListOpt]) ListWithContext(ctx context.Context, options ListOpt) ([]T, error) {
+	items, _, err := r.ListWithPagination(ctx, options)
+	return items, err
+}
+
+// ListWithPagination returns a page of items and the options of the
+// neighbouring pages from the response's Link header
+func (r *Resource[T, ListOpt]) ListWithPagination(ctx context.Context, options ListOpt) ([]T, *Pagination, error) {
+	items := make([]T, 0)
+	headers, err := r.client.createAndDoGetHeadersWithContext(ctx, "GET", r.path, nil, options, &items)
+	if err != nil {
+		return items, nil, err
+	}
+	pagination, err := extractPagination(headers.Get("Link"))
+	return items, pagination, err
+}
+
+// ListAll returns the items of every page, overriding the page and per_page
+// of options
+func (r *Resource[T, ListOpt]) ListAll(ctx context.Context, options ListOpt) ([]T, error) {
+	values, err := query.Values(options)
+	if err != nil {
+		return nil, err
+	}
+	values.Set("per_page", strconv.Itoa(maxBatchSize))
+
+	var all []T
+	for page := 1; ; page++ {
+		values.Set("page", strconv.Itoa(page))
+		items := make([]T, 0)
+		err := r.client.CreateAndDoWithContext(ctx, "GET", r.path+"?"+values.Encode(), nil, nil, &items)
+		if err != nil {
+			return all, err
+		}
+		all = append(all, items...)
+		if len(items) < maxBatchSize {
+			return all, nil
+		}
+	}
+}
+
+// Get returns the item id
+func (r *Resource[T, ListOpt]) Get(id int64, options interface{}) (*T, error) {
+	return r.GetWithContext(context.Background(), id, options)
+}
+
+// GetWithContext returns the item id
+func (r *Resource[T, ListOpt]) GetWithContext(ctx context.Context, id int64, options interface{}) (*T, error) {
+	resource := new(T)
+	err := r.client.GetWithContext(ctx, r.itemPath(id), resource, options)
+	return resource, err
+}
+
+// Create creates item
+func (r *Resource[T, ListOpt]) Create(item T) (*T, error) {
+	return r.CreateWithContext(context.Background(), item)
+}
+
+// CreateWithContext creates item
+func (r *Resource[T, ListOpt]) CreateWithContext(ctx context.Context, item T) (*T, error) {
+	resource := new(T)
+	err := r.client.PostWithContext(ctx, r.path, item, resource)
+	return resource, err
+}
+
+// Update updates the item id with the fields of item
+func (r *Resource[T, ListOpt]) Update(id int64, item T) (*T, error) {
+	return r.UpdateWithContext(context.Background(), id, item)
+}
+
+// UpdateWithContext updates the item id with the fields of item
+func (r *Resource[T, ListOpt]) UpdateWithContext(ctx context.Context, id int64, item T) (*T, error) {
+	resource := new(T)
+	err := r.client.PutWithContext(ctx, r.itemPath(id), item, resource)
+	return resource, err
+}
+
+// Delete deletes the item id, options is usually a DeleteOption
+func (r *Resource[T, ListOpt]) Delete(id int64, options interface{}) (*T, error) {
+	return r.DeleteWithContext(context.Background(), id, options)
+}
+
+// DeleteWithContext deletes the item id, options is usually a DeleteOption
+func (r *Resource[T, ListOpt]) DeleteWithContext(ctx context.Context, id int64, options interface{}) (*T, error) {
+	resource := new(T)
+	err := r.client.DeleteWithContext(ctx, r.itemPath(id), options, resource)
+	return resource, err
+}
+
+// Batch creates, updates and deletes items in one request
+func (r *Resource[T, ListOpt]) Batch(data ResourceBatchOption[T]) (*ResourceBatchResource[T], error) {
+	return r.BatchWithContext(context.Background(), data)
+}
+
+// BatchWithContext creates, updates and deletes items in one request
+func (r *Resource[T, ListOpt]) BatchWithContext(ctx context.Context, data ResourceBatchOption[T]) (*ResourceBatchResource[T], error) {
+	resource := new(ResourceBatchResource[T])
+	err := r.client.PostWithContext(ctx, r.path+"/batch", data, resource)
+	return resource, err
+}
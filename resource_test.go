@@ -0,0 +1,102 @@
+package woocommerce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+type testBooking struct {
+	ID     int64  `json:"id,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+type testBookingListOption struct {
+	ListOptions
+	Status string `url:"status,omitempty"`
+}
+
+func TestResource(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc-bookings/v1/bookings", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var booking testBooking
+			json.NewDecoder(r.Body).Decode(&booking)
+			booking.ID = 500
+			json.NewEncoder(w).Encode(booking)
+			return
+		}
+		if r.URL.Query().Get("status") != "paid" {
+			t.Errorf("status = %q", r.URL.Query().Get("status"))
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		if perPage == 0 {
+			perPage = 10
+		}
+		bookings := []testBooking{}
+		for id := (page-1)*perPage + 1; id <= page*perPage && id <= 150; id++ {
+			bookings = append(bookings, testBooking{ID: int64(id), Status: "paid"})
+		}
+		if page*perPage < 150 {
+			w.Header().Set("Link", fmt.Sprintf(`<https://%s%s?page=%d>; rel="next"`, r.Host, r.URL.Path, page+1))
+		}
+		json.NewEncoder(w).Encode(bookings)
+	})
+	mux.HandleFunc("/wp-json/wc-bookings/v1/bookings/7", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"id":7,"status":"paid"}`))
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"status":"cancelled"}` {
+				t.Errorf("update body = %s", body)
+			}
+			w.Write([]byte(`{"id":7,"status":"cancelled"}`))
+		case http.MethodDelete:
+			w.Write([]byte(`{"id":7,"status":"cancelled"}`))
+		}
+	})
+	mux.HandleFunc("/wp-json/wc-bookings/v1/bookings/batch", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"create":[{"id":501}],"delete":[{"id":8}]}`))
+	})
+	c := newTestClient(t, mux)
+	bookings := NewResource[testBooking, testBookingListOption](c, "/wp-json/wc-bookings/v1/bookings")
+	ctx := context.Background()
+
+	page, pagination, err := bookings.ListWithPagination(ctx, testBookingListOption{ListOptions: ListOptions{Page: 2}, Status: "paid"})
+	if err != nil || len(page) != 10 || page[0].ID != 11 || pagination.NextPageOptions == nil || pagination.NextPageOptions.Page != 3 {
+		t.Fatalf("ListWithPagination() = %v, %+v, %v", page, pagination, err)
+	}
+	all, err := bookings.ListAll(ctx, testBookingListOption{ListOptions: ListOptions{Page: 5}, Status: "paid"})
+	if err != nil || len(all) != 150 || all[149].ID != 150 {
+		t.Fatalf("ListAll() returned %d bookings, %v", len(all), err)
+	}
+
+	if booking, err := bookings.Get(7, nil); err != nil || booking.Status != "paid" {
+		t.Errorf("Get() = %+v, %v", booking, err)
+	}
+	if booking, err := bookings.Create(testBooking{Status: "unpaid"}); err != nil || booking.ID != 500 {
+		t.Errorf("Create() = %+v, %v", booking, err)
+	}
+	if booking, err := bookings.UpdateWithContext(ctx, 7, testBooking{Status: "cancelled"}); err != nil || booking.Status != "cancelled" {
+		t.Errorf("Update() = %+v, %v", booking, err)
+	}
+	if _, err := bookings.Delete(7, DeleteOption{Force: true}); err != nil {
+		t.Errorf("Delete() err = %v", err)
+	}
+	result, err := bookings.Batch(ResourceBatchOption[testBooking]{Create: []testBooking{{Status: "paid"}}, Delete: []int64{8}})
+	if err != nil || len(result.Create) != 1 || result.Create[0].ID != 501 || result.Delete[0].ID != 8 {
+		t.Errorf("Batch() = %+v, %v", result, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := bookings.GetWithContext(ctx, 7, nil); err == nil {
+		t.Error("GetWithContext() with a cancelled context succeeded")
+	}
+}
@@ -0,0 +1,162 @@
+package woocommerce
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrSKUNotFound is returned when no product or variation has the SKU
+var ErrSKUNotFound = errors.New("sku not found")
+
+const defaultSKUCacheTTL = 15 * time.Minute
+
+// SKURef is what a SKU resolves to, VariationID is zero for products
+type SKURef struct {
+	ProductID   int64
+	VariationID int64
+}
+
+type skuCacheEntry struct {
+	ref     SKURef
+	found   bool
+	expires time.Time
+}
+
+// SKUCache resolves SKUs to product and variation IDs and remembers the answers,
+// misses included, for TTL. It is safe for concurrent use.
+type SKUCache struct {
+	client *Client
+	// TTL is how long answers are remembered, 15 minutes by default.
+	TTL time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]skuCacheEntry
+	// warmMu keeps concurrent resolutions of the same SKUs from querying twice
+	warmMu sync.Mutex
+	now    func() time.Time
+}
+
+// NewSKUCache returns an empty cache resolving through the client's product services
+func NewSKUCache(c *Client, ttl time.Duration) *SKUCache {
+	if ttl <= 0 {
+		ttl = defaultSKUCacheTTL
+	}
+	return &SKUCache{
+		client:  c,
+		TTL:     ttl,
+		entries: map[string]skuCacheEntry{},
+		now:     time.Now,
+	}
+}
+
+// Resolve returns the IDs the SKU belongs to, or ErrSKUNotFound
+func (s *SKUCache) Resolve(sku string) (SKURef, error) {
+	refs, err := s.ResolveMany([]string{sku})
+	if err != nil {
+		return SKURef{}, err
+	}
+	ref, ok := refs[sku]
+	if !ok {
+		return SKURef{}, fmt.Errorf("%q: %w", sku, ErrSKUNotFound)
+	}
+	return ref, nil
+}
+
+// ResolveMany returns the IDs of the SKUs that exist, querying only the ones that
+// are not cached
+func (s *SKUCache) ResolveMany(skus []string) (map[string]SKURef, error) {
+	refs := make(map[string]SKURef, len(skus))
+	missing := s.lookup(skus, refs)
+	if len(missing) == 0 {
+		return refs, nil
+	}
+	if err := s.warm(missing, false); err != nil {
+		return refs, err
+	}
+	s.lookup(missing, refs)
+	return refs, nil
+}
+
+// lookup fills refs with the cached SKUs and returns the ones to query
+func (s *SKUCache) lookup(skus []string, refs map[string]SKURef) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := s.now()
+	var missing []string
+	for _, sku := range skus {
+		entry, ok := s.entries[sku]
+		if !ok || now.After(entry.expires) {
+			missing = append(missing, sku)
+			continue
+		}
+		if entry.found {
+			refs[sku] = entry.ref
+		}
+	}
+	return missing
+}
+
+// Warm queries skus, whether cached or not, so that later resolutions are served
+// from the cache
+func (s *SKUCache) Warm(skus []string) error {
+	return s.warm(skus, true)
+}
+
+func (s *SKUCache) warm(skus []string, force bool) error {
+	s.warmMu.Lock()
+	defer s.warmMu.Unlock()
+	if !force {
+		// another goroutine may have resolved them while we waited
+		skus = s.lookup(skus, map[string]SKURef{})
+	}
+	if len(skus) == 0 {
+		return nil
+	}
+
+	found := map[string]SKURef{}
+	products, err := s.client.Product.ListBySKUs(skus)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		if product.Type == ProductTypeVariation {
+			found[product.SKU] = SKURef{ProductID: product.ParentID, VariationID: product.ID}
+		} else {
+			found[product.SKU] = SKURef{ProductID: product.ID}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires := s.now().Add(s.TTL)
+	for _, sku := range skus {
+		ref, ok := found[sku]
+		s.entries[sku] = skuCacheEntry{ref: ref, found: ok, expires: expires}
+	}
+	return nil
+}
+
+// Set records a resolution, for instance right after creating a product
+func (s *SKUCache) Set(sku string, ref SKURef) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[sku] = skuCacheEntry{ref: ref, found: true, expires: s.now().Add(s.TTL)}
+}
+
+// Forget drops the cached resolutions of skus
+func (s *SKUCache) Forget(skus ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sku := range skus {
+		delete(s.entries, sku)
+	}
+}
+
+// Clear drops every cached resolution
+func (s *SKUCache) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]skuCacheEntry{}
+}
@@ -0,0 +1,86 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSKUCache(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		all := []Product{{ID: 1, SKU: "A"}, {ID: 2, SKU: "B"}, {ID: 3, Type: ProductTypeVariable, SKU: "V"},
+			{ID: 31, Type: ProductTypeVariation, ParentID: 3, SKU: "V-RED"}}
+		var products []Product
+		for _, p := range all {
+			// like WooCommerce, the sku filter is the only one matching variations
+			sku, kind := r.URL.Query().Get("sku"), r.URL.Query().Get("type")
+			if sku == "" && p.Type == ProductTypeVariation {
+				continue
+			}
+			if (sku == "" || strings.Contains(","+sku+",", ","+p.SKU+",")) && (kind == "" || kind == string(p.Type)) {
+				products = append(products, p)
+			}
+		}
+		json.NewEncoder(w).Encode(products)
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/3/variations", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		json.NewEncoder(w).Encode([]ProductVariation{{ID: 31, SKU: "V-RED"}})
+	})
+	c := newTestClient(t, mux)
+
+	if product, err := c.Product.GetBySKU("B"); err != nil || product.ID != 2 {
+		t.Errorf("GetBySKU(B) = %+v, %v", product, err)
+	}
+	if _, err := c.Product.GetBySKU("nope"); !errors.Is(err, ErrSKUNotFound) {
+		t.Errorf("GetBySKU(nope) err = %v", err)
+	}
+	if variation, err := c.ProductVariation.GetBySKU(3, "V-RED"); err != nil || variation.ID != 31 {
+		t.Errorf("variation GetBySKU = %+v, %v", variation, err)
+	}
+
+	cache := NewSKUCache(c, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	atomic.StoreInt32(&requests, 0)
+
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			refs, err := cache.ResolveMany([]string{"A", "B", "V-RED", "MISSING"})
+			if err != nil || len(refs) != 3 || refs["V-RED"] != (SKURef{ProductID: 3, VariationID: 31}) {
+				t.Errorf("ResolveMany() = %v, %v", refs, err)
+			}
+		}()
+	}
+	wg.Wait()
+	// variations are matched by the products query too
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("resolving concurrently took %d requests, want 1", n)
+	}
+
+	if _, err := cache.Resolve("MISSING"); !errors.Is(err, ErrSKUNotFound) {
+		t.Errorf("Resolve(MISSING) err = %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("cached miss queried again, %d requests", n)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if ref, err := cache.Resolve("A"); err != nil || ref.ProductID != 1 {
+		t.Errorf("Resolve(A) after expiry = %+v, %v", ref, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expired entry was not refreshed, %d requests", n)
+	}
+}
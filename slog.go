@@ -0,0 +1,44 @@
+package woocommerce
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SlogLogger adapts a *slog.Logger to LeveledLoggerInterface. Requests and
+// responses are logged with structured attributes rather than formatted messages.
+type SlogLogger struct {
+	Logger *slog.Logger
+}
+
+// WithSlog logs through logger, request and response bodies are logged at debug level
+func WithSlog(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.log = &SlogLogger{Logger: logger}
+	}
+}
+
+// Debugf logs a debug message using Printf conventions.
+func (l *SlogLogger) Debugf(format string, v ...interface{}) {
+	l.Logger.Debug(fmt.Sprintf(format, v...))
+}
+
+// Errorf logs an error message using Printf conventions.
+func (l *SlogLogger) Errorf(format string, v ...interface{}) {
+	l.Logger.Error(fmt.Sprintf(format, v...))
+}
+
+// Infof logs an informational message using Printf conventions.
+func (l *SlogLogger) Infof(format string, v ...interface{}) {
+	l.Logger.Info(fmt.Sprintf(format, v...))
+}
+
+// Warnf logs a warning message using Printf conventions.
+func (l *SlogLogger) Warnf(format string, v ...interface{}) {
+	l.Logger.Warn(fmt.Sprintf(format, v...))
+}
+
+func (l *SlogLogger) debugEnabled(ctx context.Context) bool {
+	return l.Logger.Enabled(ctx, slog.LevelDebug)
+}
@@ -0,0 +1,401 @@
+package woocommerce
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultStockAdjustAttempts = 3
+	defaultStockRetryDelay     = 200 * time.Millisecond
+)
+
+var (
+	// ErrStockNotManaged is returned when adjusting an item that does not manage its stock
+	ErrStockNotManaged = errors.New("stock is not managed")
+	// ErrStockConflict is returned when the item kept changing while AdjustStock tried to write it
+	ErrStockConflict = errors.New("stock changed concurrently")
+	// ErrInsufficientStock is returned by Reserve when the item cannot cover the reservation
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+// Inventory adjusts, reserves, syncs and reports on the stock of products and variations
+type Inventory struct {
+	client *Client
+	// MaxAttempts is the number of times AdjustStock tries before returning ErrStockConflict.
+	MaxAttempts int
+	// RetryDelay is multiplied by the attempt number between AdjustStock attempts.
+	RetryDelay time.Duration
+
+	sleep func(time.Duration)
+	mu    sync.Mutex
+	locks map[[2]int64]*sync.Mutex
+}
+
+// NewInventory returns an Inventory working through the client's product services
+func NewInventory(c *Client) *Inventory {
+	return &Inventory{
+		client:      c,
+		MaxAttempts: defaultStockAdjustAttempts,
+		RetryDelay:  defaultStockRetryDelay,
+		sleep:       time.Sleep,
+		locks:       map[[2]int64]*sync.Mutex{},
+	}
+}
+
+// stockState is the stock of a product or variation as read from the store
+type stockState struct {
+	managed         bool
+	quantity        int64
+	backorders      BackorderPolicy
+	dateModifiedGmt string
+}
+
+func (i *Inventory) readStock(productID, variationID int64) (stockState, error) {
+	if variationID != 0 {
+		variation, err := i.client.ProductVariation.Get(productID, variationID, nil)
+		if err != nil {
+			return stockState{}, err
+		}
+		return stockState{variation.ManageStock && variation.StockQuantity != nil, deref(variation.StockQuantity), variation.Backorders, variation.DateModifiedGmt}, nil
+	}
+	product, err := i.client.Product.Get(productID, nil)
+	if err != nil {
+		return stockState{}, err
+	}
+	return stockState{product.ManageStock && product.StockQuantity != nil, deref(product.StockQuantity), product.Backorders, product.DateModifiedGmt}, nil
+}
+
+func (i *Inventory) writeStock(productID, variationID, quantity int64) error {
+	if variationID != 0 {
+		_, err := i.client.ProductVariation.Update(productID, &ProductVariation{ID: variationID, StockQuantity: Int64(quantity)})
+		return err
+	}
+	_, err := i.client.Product.Update(&Product{ID: productID, StockQuantity: Int64(quantity)})
+	return err
+}
+
+func deref(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// lock serialises the adjustments of one item made through this Inventory
+func (i *Inventory) lock(productID, variationID int64) func() {
+	i.mu.Lock()
+	key := [2]int64{productID, variationID}
+	l, ok := i.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		i.locks[key] = l
+	}
+	i.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// AdjustStock adds delta, which may be negative, to the stock of a product, or of
+// one of its variations when variationID is not zero, and returns the new quantity.
+// The item is read, then read again right before writing: when its date_modified
+// changed in between, the adjustment starts over, up to MaxAttempts times.
+// WooCommerce has no conditional writes, so this narrows the window in which
+// another process can lose an update rather than closing it; adjustments made
+// through the same Inventory are serialised.
+func (i *Inventory) AdjustStock(productID, variationID, delta int64) (int64, error) {
+	return i.adjust(productID, variationID, delta, false)
+}
+
+func (i *Inventory) adjust(productID, variationID, delta int64, reserve bool) (int64, error) {
+	unlock := i.lock(productID, variationID)
+	defer unlock()
+
+	attempts := i.MaxAttempts
+	if attempts < 1 {
+		attempts = defaultStockAdjustAttempts
+	}
+	for attempt := 1; attempt <= attempts; attempt++ {
+		state, err := i.readStock(productID, variationID)
+		if err != nil {
+			return 0, err
+		}
+		if !state.managed {
+			return 0, fmt.Errorf("product %d variation %d: %w", productID, variationID, ErrStockNotManaged)
+		}
+		quantity := state.quantity + delta
+		if reserve && quantity < 0 && state.backorders != BackorderPolicyYes && state.backorders != BackorderPolicyNotify {
+			return state.quantity, fmt.Errorf("product %d variation %d has %d in stock: %w", productID, variationID, state.quantity, ErrInsufficientStock)
+		}
+
+		check, err := i.readStock(productID, variationID)
+		if err != nil {
+			return 0, err
+		}
+		if check.dateModifiedGmt == state.dateModifiedGmt && check.quantity == state.quantity {
+			if err := i.writeStock(productID, variationID, quantity); err != nil {
+				return 0, err
+			}
+			return quantity, nil
+		}
+		if attempt < attempts {
+			i.sleep(time.Duration(attempt) * i.RetryDelay)
+		}
+	}
+	return 0, fmt.Errorf("product %d variation %d: %w", productID, variationID, ErrStockConflict)
+}
+
+// StockReservation holds stock taken by Reserve until it is committed or released
+type StockReservation struct {
+	inventory   *Inventory
+	ProductID   int64
+	VariationID int64
+	Quantity    int64
+
+	mu   sync.Mutex
+	done bool
+}
+
+// Reserve takes quantity out of the stock of an item, failing with ErrInsufficientStock
+// when the item has less and does not allow backorders. The stock is given back by
+// Release, Commit keeps it taken.
+func (i *Inventory) Reserve(productID, variationID, quantity int64) (*StockReservation, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("reservation quantity must be positive, got %d", quantity)
+	}
+	if _, err := i.adjust(productID, variationID, -quantity, true); err != nil {
+		return nil, err
+	}
+	return &StockReservation{inventory: i, ProductID: productID, VariationID: variationID, Quantity: quantity}, nil
+}
+
+// Commit keeps the reserved stock taken, typically once the order is placed
+func (r *StockReservation) Commit() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done = true
+}
+
+// Release gives the reserved stock back, unless the reservation was committed or
+// already released
+func (r *StockReservation) Release() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return nil
+	}
+	if _, err := r.inventory.AdjustStock(r.ProductID, r.VariationID, r.Quantity); err != nil {
+		return err
+	}
+	r.done = true
+	return nil
+}
+
+// StockSyncError reports an item whose stock WooCommerce refused to update
+type StockSyncError struct {
+	SKU string
+	Err error
+}
+
+func (e StockSyncError) Error() string {
+	return fmt.Sprintf("sku %q: %v", e.SKU, e.Err)
+}
+
+func (e StockSyncError) Unwrap() error {
+	return e.Err
+}
+
+// StockSyncResult reports the SKUs handled by SyncStock
+type StockSyncResult struct {
+	Updated   []string
+	Unchanged []string
+	// Unknown holds the SKUs matching no product or variation.
+	Unknown []string
+	// Failed holds the items the batch endpoints rejected, the others are still updated.
+	Failed []StockSyncError
+}
+
+// SyncStock sets the stock of the products and variations identified by the SKUs
+// of quantities, enabling stock management where needed. Products are looked up
+// by SKU a hundred at a time, variations among the variable products, and the
+// changes are written with the batch endpoints. Items WooCommerce rejects are
+// reported in Failed rather than Updated.
+func (i *Inventory) SyncStock(quantities map[string]int64) (*StockSyncResult, error) {
+	skus := make([]string, 0, len(quantities))
+	for sku := range quantities {
+		skus = append(skus, sku)
+	}
+	sort.Strings(skus)
+
+	result := &StockSyncResult{}
+	found := map[string]bool{}
+	needsUpdate := func(sku string, managed bool, quantity *int64) bool {
+		found[sku] = true
+		if managed && quantity != nil && *quantity == quantities[sku] {
+			result.Unchanged = append(result.Unchanged, sku)
+			return false
+		}
+		return true
+	}
+
+	matches, err := i.client.Product.ListBySKUs(skus)
+	if err != nil {
+		return nil, err
+	}
+	var products []Product
+	var productSKUs []string
+	for _, product := range matches {
+		if found[product.SKU] {
+			continue
+		}
+		if needsUpdate(product.SKU, product.ManageStock, product.StockQuantity) {
+			products = append(products, Product{ID: product.ID, ManageStock: true, StockQuantity: Int64(quantities[product.SKU])})
+			productSKUs = append(productSKUs, product.SKU)
+		}
+	}
+
+	var parentIDs []int64
+	variations := map[int64][]ProductVariation{}
+	variationSKUs := map[int64][]string{}
+	if len(found) < len(skus) {
+		parents, err := listAllPages(func(o ListOptions) ([]Product, error) {
+			return i.client.Product.List(ProductListOption{ListOptions: o, Type: ProductTypeVariable})
+		})
+		if err != nil {
+			return nil, err
+		}
+		matrix := NewVariationMatrix(i.client, nil)
+		for _, parent := range parents {
+			if len(found) == len(skus) {
+				break
+			}
+			children, err := matrix.listAll(parent.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, variation := range children {
+				if _, wanted := quantities[variation.SKU]; !wanted || found[variation.SKU] {
+					continue
+				}
+				if needsUpdate(variation.SKU, variation.ManageStock, variation.StockQuantity) {
+					if len(variations[parent.ID]) == 0 {
+						parentIDs = append(parentIDs, parent.ID)
+					}
+					variations[parent.ID] = append(variations[parent.ID], ProductVariation{ID: variation.ID, ManageStock: true, StockQuantity: Int64(quantities[variation.SKU])})
+					variationSKUs[parent.ID] = append(variationSKUs[parent.ID], variation.SKU)
+				}
+			}
+		}
+	}
+	for _, sku := range skus {
+		if !found[sku] {
+			result.Unknown = append(result.Unknown, sku)
+		}
+	}
+
+	err = syncStockBatches(result, productSKUs, products, func(updates []Product) ([]*Product, error) {
+		resource, err := i.client.Product.Batch(ProductBatchOption{Update: updates})
+		return resource.Update, err
+	}, productError)
+	if err != nil {
+		return result, err
+	}
+	for _, parentID := range parentIDs {
+		parentID := parentID
+		err := syncStockBatches(result, variationSKUs[parentID], variations[parentID], func(updates []ProductVariation) ([]*ProductVariation, error) {
+			resource, err := i.client.ProductVariation.Batch(parentID, ProductVariationBatchOption{Update: updates})
+			return resource.Update, err
+		}, variationError)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// syncStockBatches sends updates a batch at a time and reports each of their SKUs
+// as updated or failed depending on the item WooCommerce returned for it
+func syncStockBatches[T any](result *StockSyncResult, skus []string, updates []T, send func([]T) ([]*T, error), itemError func(*T) *BatchItemError) error {
+	return batchChunks(nil, updates, nil, func(_, updates []T, _ []int64) error {
+		received, err := send(updates)
+		if err != nil {
+			return err
+		}
+		for j, sku := range skus[:len(updates)] {
+			if j < len(received) && received[j] != nil && itemError(received[j]) != nil {
+				result.Failed = append(result.Failed, StockSyncError{SKU: sku, Err: itemError(received[j])})
+			} else {
+				result.Updated = append(result.Updated, sku)
+			}
+		}
+		skus = skus[len(updates):]
+		return nil
+	})
+}
+
+// LowStockItem is a product or variation at or below its low stock threshold
+type LowStockItem struct {
+	ProductID     int64
+	VariationID   int64
+	SKU           string
+	Name          string
+	StockQuantity int64
+	Threshold     int64
+}
+
+// LowStockReport lists the items managing their stock whose quantity is at or
+// below their LowStockAmount. Variations without one use their parent's, and items
+// without either use defaultThreshold, the store's "Low stock threshold" setting.
+func (i *Inventory) LowStockReport(defaultThreshold int64) ([]LowStockItem, error) {
+	products, err := listAllPages(func(o ListOptions) ([]Product, error) {
+		return i.client.Product.List(ProductListOption{ListOptions: o})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var report []LowStockItem
+	matrix := NewVariationMatrix(i.client, nil)
+	for _, product := range products {
+		threshold := defaultThreshold
+		if product.LowStockAmount != nil {
+			threshold = *product.LowStockAmount
+		}
+		if product.ManageStock && product.StockQuantity != nil && *product.StockQuantity <= threshold {
+			report = append(report, LowStockItem{
+				ProductID:     product.ID,
+				SKU:           product.SKU,
+				Name:          product.Name,
+				StockQuantity: *product.StockQuantity,
+				Threshold:     threshold,
+			})
+		}
+		if product.Type != ProductTypeVariable {
+			continue
+		}
+		variations, err := matrix.listAll(product.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, variation := range variations {
+			variationThreshold := threshold
+			if variation.LowStockAmount != nil {
+				variationThreshold = *variation.LowStockAmount
+			}
+			if variation.ManageStock && variation.StockQuantity != nil && *variation.StockQuantity <= variationThreshold {
+				report = append(report, LowStockItem{
+					ProductID:     product.ID,
+					VariationID:   variation.ID,
+					SKU:           variation.SKU,
+					Name:          product.Name,
+					StockQuantity: *variation.StockQuantity,
+					Threshold:     variationThreshold,
+				})
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,206 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stockTestStore serves product 1 (simple, SKU A), product 2 (variable) with
+// variation 21 (SKU B) and product 3 (not managing stock, SKU C).
+type stockTestStore struct {
+	mu       sync.Mutex
+	stock    map[int64]int64
+	modified map[int64]int
+	// interfere, when positive, bumps product 1 between reads that many times
+	interfere int
+	batches   []json.RawMessage
+	// rejected holds the IDs the batch endpoints answer with an item error
+	rejected map[int64]bool
+}
+
+func (s *stockTestStore) product(id int64) Product {
+	p := Product{ID: id, ManageStock: true, StockQuantity: Int64(s.stock[id]), DateModifiedGmt: time.Unix(int64(s.modified[id]), 0).UTC().Format("2006-01-02T15:04:05")}
+	switch id {
+	case 1:
+		p.SKU, p.LowStockAmount = "A", Int64(5)
+	case 2:
+		p.Type, p.ManageStock, p.StockQuantity = ProductTypeVariable, false, nil
+	case 3:
+		p.SKU, p.ManageStock, p.StockQuantity = "C", false, nil
+	}
+	return p
+}
+
+func (s *stockTestStore) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/products/1", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.Method == http.MethodPut {
+			var p Product
+			json.NewDecoder(r.Body).Decode(&p)
+			s.stock[1] = *p.StockQuantity
+			s.modified[1]++
+		} else if s.interfere > 0 {
+			s.interfere--
+			s.modified[1]++
+		}
+		json.NewEncoder(w).Encode(s.product(1))
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var products []Product
+		for _, id := range []int64{1, 2, 3} {
+			p := s.product(id)
+			sku := r.URL.Query().Get("sku")
+			if sku != "" && !strings.Contains(","+sku+",", ","+p.SKU+",") {
+				continue
+			}
+			if r.URL.Query().Get("type") != "" && string(p.Type) != r.URL.Query().Get("type") {
+				continue
+			}
+			products = append(products, p)
+		}
+		json.NewEncoder(w).Encode(products)
+	})
+	mux.HandleFunc("/wp-json/wc/v3/products/2/variations", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		json.NewEncoder(w).Encode([]ProductVariation{{ID: 21, SKU: "B", ManageStock: true, StockQuantity: Int64(s.stock[21])}})
+	})
+	record := func(w http.ResponseWriter, r *http.Request) {
+		var raw json.RawMessage
+		json.NewDecoder(r.Body).Decode(&raw)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.batches = append(s.batches, raw)
+		var batch struct{ Update []struct{ ID int64 } }
+		json.Unmarshal(raw, &batch)
+		var updated []json.RawMessage
+		for _, item := range batch.Update {
+			if s.rejected[item.ID] {
+				updated = append(updated, json.RawMessage(`{"id":0,"error":{"code":"woocommerce_rest_invalid_id","message":"Invalid ID.","data":{"status":400}}}`))
+			} else {
+				updated = append(updated, json.RawMessage(fmt.Sprintf(`{"id":%d}`, item.ID)))
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"update": updated})
+	}
+	mux.HandleFunc("/wp-json/wc/v3/products/batch", record)
+	mux.HandleFunc("/wp-json/wc/v3/products/2/variations/batch", record)
+	return mux
+}
+
+func newStockTestInventory(t *testing.T) (*Inventory, *stockTestStore) {
+	store := &stockTestStore{stock: map[int64]int64{1: 10, 21: 2}, modified: map[int64]int{}}
+	inventory := NewInventory(newTestClient(t, store.handler(t)))
+	inventory.sleep = func(time.Duration) {}
+	return inventory, store
+}
+
+func TestInventory_AdjustStock(t *testing.T) {
+	inventory, store := newStockTestInventory(t)
+
+	var wg sync.WaitGroup
+	for n := 0; n < 5; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := inventory.AdjustStock(1, 0, -1); err != nil {
+				t.Errorf("AdjustStock() err = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if store.stock[1] != 5 {
+		t.Errorf("stock after 5 concurrent decrements = %d, want 5", store.stock[1])
+	}
+
+	store.interfere = 1
+	if quantity, err := inventory.AdjustStock(1, 0, 3); err != nil || quantity != 8 {
+		t.Errorf("AdjustStock() after a conflict = %d, %v", quantity, err)
+	}
+
+	store.interfere = 100
+	if _, err := inventory.AdjustStock(1, 0, 1); !errors.Is(err, ErrStockConflict) {
+		t.Errorf("AdjustStock() under constant changes err = %v", err)
+	}
+}
+
+func TestInventory_Reserve(t *testing.T) {
+	inventory, store := newStockTestInventory(t)
+
+	if _, err := inventory.Reserve(1, 0, 11); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("Reserve() beyond stock err = %v", err)
+	}
+	reservation, err := inventory.Reserve(1, 0, 4)
+	if err != nil || store.stock[1] != 6 {
+		t.Fatalf("Reserve() = %v, stock %d", err, store.stock[1])
+	}
+	reservation.Release()
+	reservation.Release()
+	if store.stock[1] != 10 {
+		t.Errorf("stock after releasing twice = %d, want 10", store.stock[1])
+	}
+}
+
+func TestInventory_SyncStock(t *testing.T) {
+	inventory, store := newStockTestInventory(t)
+
+	result, err := inventory.SyncStock(map[string]int64{"A": 10, "B": 7, "C": 1, "Z": 3})
+	if err != nil {
+		t.Fatalf("SyncStock() err = %v", err)
+	}
+	if strings.Join(result.Updated, ",") != "C,B" || strings.Join(result.Unchanged, ",") != "A" || strings.Join(result.Unknown, ",") != "Z" {
+		t.Errorf("SyncStock() = %+v", result)
+	}
+	if len(store.batches) != 2 {
+		t.Fatalf("sent %d batches", len(store.batches))
+	}
+	var products ProductBatchOption
+	json.Unmarshal(store.batches[0], &products)
+	if len(products.Update) != 1 || products.Update[0].ID != 3 || !products.Update[0].ManageStock || *products.Update[0].StockQuantity != 1 {
+		t.Errorf("product batch = %s", store.batches[0])
+	}
+}
+
+func TestInventory_SyncStockItemError(t *testing.T) {
+	inventory, store := newStockTestInventory(t)
+	store.rejected = map[int64]bool{21: true}
+
+	result, err := inventory.SyncStock(map[string]int64{"B": 7, "C": 1})
+	if err != nil {
+		t.Fatalf("SyncStock() err = %v", err)
+	}
+	if strings.Join(result.Updated, ",") != "C" || len(result.Failed) != 1 || result.Failed[0].SKU != "B" {
+		t.Fatalf("SyncStock() = %+v", result)
+	}
+	var itemErr *BatchItemError
+	if !errors.As(result.Failed[0], &itemErr) || itemErr.Code != "woocommerce_rest_invalid_id" {
+		t.Errorf("Failed[0] = %v", result.Failed[0])
+	}
+}
+
+func TestInventory_LowStockReport(t *testing.T) {
+	inventory, _ := newStockTestInventory(t)
+
+	report, err := inventory.LowStockReport(3)
+	if err != nil {
+		t.Fatalf("LowStockReport() err = %v", err)
+	}
+	if len(report) != 1 || report[0].VariationID != 21 || report[0].Threshold != 3 {
+		t.Errorf("LowStockReport(3) = %+v", report)
+	}
+
+	report, _ = inventory.LowStockReport(0)
+	if len(report) != 0 {
+		t.Errorf("LowStockReport(0) = %+v", report)
+	}
+}
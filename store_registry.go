@@ -0,0 +1,255 @@
+package woocommerce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStoreNotFound is returned for stores that are not in a StoreRegistry
+var ErrStoreNotFound = errors.New("woocommerce: store not found")
+
+// StoreConfig describes one store of a StoreRegistry
+type StoreConfig struct {
+	// Name identifies the store in the registry.
+	Name string
+	// ShopName is the store's domain, e.g. "shop.example.com".
+	ShopName string
+	App      App
+	Options  []Option
+	// RequestsPerSecond limits the rate of requests sent to the store, 0 for no limit.
+	RequestsPerSecond float64
+	// MaxConcurrent caps the requests in flight to the store, 0 for no cap.
+	MaxConcurrent int
+}
+
+// StoreRegistry holds the credentials of several stores and builds their
+// clients on first use. Limits are enforced per store across all its clients,
+// including the ones built before a credential rotation.
+type StoreRegistry struct {
+	// Parallelism is how many stores FanOut works on at once, 8 by default.
+	Parallelism int
+
+	mu     sync.Mutex
+	stores map[string]*registeredStore
+}
+
+type registeredStore struct {
+	config  StoreConfig
+	client  *Client
+	limiter *storeLimiter
+}
+
+// NewStoreRegistry returns a registry of the given stores
+func NewStoreRegistry(stores ...StoreConfig) *StoreRegistry {
+	r := &StoreRegistry{
+		Parallelism: 8,
+		stores:      make(map[string]*registeredStore),
+	}
+	for _, store := range stores {
+		r.Register(store)
+	}
+	return r
+}
+
+// Register adds a store, or replaces the configuration of a registered one.
+// Its client is rebuilt on next use.
+func (r *StoreRegistry) Register(store StoreConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	limiter := newStoreLimiter(store.RequestsPerSecond, store.MaxConcurrent)
+	if existing, ok := r.stores[store.Name]; ok && existing.config.RequestsPerSecond == store.RequestsPerSecond && existing.config.MaxConcurrent == store.MaxConcurrent {
+		limiter = existing.limiter
+	}
+	r.stores[store.Name] = &registeredStore{config: store, limiter: limiter}
+}
+
+// Remove drops a store from the registry
+func (r *StoreRegistry) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.stores, name)
+}
+
+// Rotate replaces the credentials of a store without touching its other
+// settings. Clients already handed out keep the old credentials, get a new one
+// with Client.
+func (r *StoreRegistry) Rotate(name string, app App) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	store, ok := r.stores[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStoreNotFound, name)
+	}
+	store.config.App = app
+	store.client = nil
+	return nil
+}
+
+// Names returns the names of the registered stores, sorted
+func (r *StoreRegistry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return sortedNames(r.stores)
+}
+
+// Client returns the client of a store, building it on first use
+func (r *StoreRegistry) Client(name string) (*Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	store, ok := r.stores[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrStoreNotFound, name)
+	}
+	if store.client == nil {
+		opts := append([]Option{}, store.config.Options...)
+		if store.limiter != nil {
+			opts = append(opts, WithMiddleware(store.limiter.middleware))
+		}
+		store.client = NewClient(store.config.App, store.config.ShopName, opts...)
+	}
+	return store.client, nil
+}
+
+// StoreErrors holds the stores a FanOut failed on, by name
+type StoreErrors map[string]error
+
+func (e StoreErrors) Error() string {
+	names := sortedNames(e)
+	messages := make([]string, len(names))
+	for i, name := range names {
+		messages[i] = fmt.Sprintf("%s: %v", name, e[name])
+	}
+	return fmt.Sprintf("%d stores failed: %s", len(e), strings.Join(messages, "; "))
+}
+
+// FanOut runs fn for every store of the registry concurrently and returns the
+// results of the stores it succeeded on. When some stores fail the error is a
+// StoreErrors, the results of the other stores are still returned.
+func FanOut[T any](ctx context.Context, r *StoreRegistry, fn func(ctx context.Context, store string, c *Client) (T, error)) (map[string]T, error) {
+	names := r.Names()
+	parallelism := r.Parallelism
+	if parallelism <= 0 {
+		parallelism = len(names)
+	}
+
+	var mu sync.Mutex
+	results := make(map[string]T, len(names))
+	failed := StoreErrors{}
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				mu.Lock()
+				failed[name] = ctx.Err()
+				mu.Unlock()
+				return
+			}
+
+			result, err := func() (T, error) {
+				c, err := r.Client(name)
+				if err != nil {
+					var zero T
+					return zero, err
+				}
+				return fn(ctx, name, c)
+			}()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[name] = err
+				return
+			}
+			results[name] = result
+		}(name)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return results, failed
+	}
+	return results, nil
+}
+
+// storeLimiter spaces the requests of a store and caps how many are in flight
+type storeLimiter struct {
+	interval time.Duration
+	slots    chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newStoreLimiter(requestsPerSecond float64, maxConcurrent int) *storeLimiter {
+	if requestsPerSecond <= 0 && maxConcurrent <= 0 {
+		return nil
+	}
+	l := &storeLimiter{}
+	if requestsPerSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+func (l *storeLimiter) middleware(next CallHandler) CallHandler {
+	return func(call *Call) (*http.Response, error) {
+		ctx := call.Request.Context()
+		if l.slots != nil {
+			select {
+			case l.slots <- struct{}{}:
+				defer func() { <-l.slots }()
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		if wait := l.reserve(); wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return next(call)
+	}
+}
+
+// reserve books the next request slot and returns how long to wait for it
+func (l *storeLimiter) reserve() time.Duration {
+	if l.interval == 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	return wait
+}
+
+func sortedNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
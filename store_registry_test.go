@@ -0,0 +1,119 @@
+package woocommerce
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStoreRegistry(t *testing.T) {
+	var mu sync.Mutex
+	keys := map[string]string{}
+	var inFlight, maxInFlight int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		key, _, _ := r.BasicAuth()
+		mu.Lock()
+		keys[key] = r.URL.Path
+		mu.Unlock()
+		if key == "ck_broken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":"woocommerce_rest_cannot_view","message":"Sorry, you cannot list resources."}`))
+			return
+		}
+		w.Write([]byte(`[{"id":1},{"id":2}]`))
+	}))
+	defer server.Close()
+
+	shop := strings.TrimPrefix(server.URL, "https://")
+	testServer := func(c *Client) { c.Client = server.Client() }
+	registry := NewStoreRegistry(
+		StoreConfig{Name: "eu", ShopName: shop, App: App{CustomerKey: "ck_eu"}, Options: []Option{testServer}, MaxConcurrent: 1},
+		StoreConfig{Name: "us", ShopName: shop, App: App{CustomerKey: "ck_us"}, Options: []Option{testServer}},
+		StoreConfig{Name: "broken", ShopName: shop, App: App{CustomerKey: "ck_broken"}, Options: []Option{testServer}},
+	)
+
+	if _, err := registry.Client("nope"); !errors.Is(err, ErrStoreNotFound) {
+		t.Errorf("Client(nope) err = %v", err)
+	}
+	eu, _ := registry.Client("eu")
+	if again, _ := registry.Client("eu"); again != eu {
+		t.Error("client was not cached")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			eu.Order.List(nil)
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&maxInFlight); n != 1 {
+		t.Errorf("%d requests in flight for a store capped at 1", n)
+	}
+
+	counts, err := FanOut(context.Background(), registry, func(ctx context.Context, store string, c *Client) (int, error) {
+		orders, err := c.Order.List(OrderListOption{Status: []string{"processing"}})
+		return len(orders), err
+	})
+	var storeErrs StoreErrors
+	if !errors.As(err, &storeErrs) || len(storeErrs) != 1 || storeErrs["broken"] == nil {
+		t.Errorf("FanOut() err = %v", err)
+	}
+	if len(counts) != 2 || counts["eu"] != 2 || counts["us"] != 2 {
+		t.Errorf("FanOut() results = %v", counts)
+	}
+
+	if err := registry.Rotate("broken", App{CustomerKey: "ck_rotated"}); err != nil {
+		t.Fatalf("Rotate() err = %v", err)
+	}
+	if _, err := FanOut(context.Background(), registry, func(ctx context.Context, store string, c *Client) ([]Order, error) {
+		return c.Order.List(nil)
+	}); err != nil {
+		t.Errorf("FanOut() after rotation err = %v", err)
+	}
+	if _, ok := keys["ck_rotated"]; !ok {
+		t.Errorf("rotated credentials were not used, keys = %v", keys)
+	}
+}
+
+func TestStoreRegistry_RateLimit(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	registry := NewStoreRegistry(StoreConfig{
+		Name:              "eu",
+		ShopName:          strings.TrimPrefix(server.URL, "https://"),
+		Options:           []Option{func(c *Client) { c.Client = server.Client() }},
+		RequestsPerSecond: 50,
+	})
+	c, _ := registry.Client("eu")
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := c.Order.List(nil); err != nil {
+			t.Fatalf("List() err = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("5 requests at 50/s took %s", elapsed)
+	}
+}
@@ -2,12 +2,66 @@ package woocommerce
 
 import (
 	"fmt"
+	"strings"
 )
 
 const (
 	webhooksBasePath = "webhooks"
 )
 
+// WebhookStatus is the delivery status of a webhook
+type WebhookStatus string
+
+const (
+	WebhookStatusActive   WebhookStatus = "active"
+	WebhookStatusPaused   WebhookStatus = "paused"
+	WebhookStatusDisabled WebhookStatus = "disabled"
+)
+
+// Valid reports whether s is one of WooCommerce's built-in webhook statuses
+func (s WebhookStatus) Valid() bool {
+	switch s {
+	case WebhookStatusActive, WebhookStatusPaused, WebhookStatusDisabled:
+		return true
+	}
+	return false
+}
+
+// WebhookTopic is the "resource.event" a webhook is subscribed to, or
+// "action.<hook>" for a custom WordPress action.
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#topics
+type WebhookTopic string
+
+const (
+	WebhookTopicCouponCreated   WebhookTopic = "coupon.created"
+	WebhookTopicCouponUpdated   WebhookTopic = "coupon.updated"
+	WebhookTopicCouponDeleted   WebhookTopic = "coupon.deleted"
+	WebhookTopicCouponRestored  WebhookTopic = "coupon.restored"
+	WebhookTopicCustomerCreated WebhookTopic = "customer.created"
+	WebhookTopicCustomerUpdated WebhookTopic = "customer.updated"
+	WebhookTopicCustomerDeleted WebhookTopic = "customer.deleted"
+	WebhookTopicOrderCreated    WebhookTopic = "order.created"
+	WebhookTopicOrderUpdated    WebhookTopic = "order.updated"
+	WebhookTopicOrderDeleted    WebhookTopic = "order.deleted"
+	WebhookTopicOrderRestored   WebhookTopic = "order.restored"
+	WebhookTopicProductCreated  WebhookTopic = "product.created"
+	WebhookTopicProductUpdated  WebhookTopic = "product.updated"
+	WebhookTopicProductDeleted  WebhookTopic = "product.deleted"
+	WebhookTopicProductRestored WebhookTopic = "product.restored"
+)
+
+// Valid reports whether t is a built-in topic or a well formed "action." topic
+func (t WebhookTopic) Valid() bool {
+	switch t {
+	case WebhookTopicCouponCreated, WebhookTopicCouponUpdated, WebhookTopicCouponDeleted, WebhookTopicCouponRestored,
+		WebhookTopicCustomerCreated, WebhookTopicCustomerUpdated, WebhookTopicCustomerDeleted,
+		WebhookTopicOrderCreated, WebhookTopicOrderUpdated, WebhookTopicOrderDeleted, WebhookTopicOrderRestored,
+		WebhookTopicProductCreated, WebhookTopicProductUpdated, WebhookTopicProductDeleted, WebhookTopicProductRestored:
+		return true
+	}
+	return strings.HasPrefix(string(t), "action.") && len(t) > len("action.")
+}
+
 // WebhookService is an interface for interfacing with the webhook endpoints of
 // the WooCommerce webhooks restful API
 // https://woocommerce.github.io/woocommerce-rest-api-docs/#webhooks
@@ -18,6 +72,7 @@ type WebhookService interface {
 	Update(webhook *Webhook) (*Webhook, error)
 	Delete(webhookID int64, options interface{}) (*Webhook, error)
 	Batch(data WebhookBatchOption) (*WebhookBatchResource, error)
+	Reconcile(desired []Webhook, options WebhookReconcileOption) (*WebhookReconcileReport, error)
 }
 
 // WebhookServiceOp handles communication with the webhooks related methods of WooCommerce restful api
@@ -27,20 +82,22 @@ type WebhookServiceOp struct {
 
 // Webhook represent a  wooCommerce webhook's All  properties columns
 type Webhook struct {
-	ID              int64    `json:"id,omitempty"`
-	Name            string   `json:"name,omitempty"`
-	Status          string   `json:"status,omitempty"`
-	Topic           string   `json:"topic,omitempty"`
-	Resource        string   `json:"resource,omitempty"`
-	Event           string   `json:"event,omitempty"`
-	Hooks           []string `json:"hooks,omitempty"`
-	DeliveryUrl     string   `json:"delivery_url,omitempty"`
-	Secret          string   `json:"secret,omitempty"`
-	DateCreated     string   `json:"date_created,omitempty"`
-	DateCreatedGmt  string   `json:"date_created_gmt,omitempty"`
-	DateModified    string   `json:"date_modified,omitempty"`
-	DateModifiedGmt string   `json:"date_modified_gmt,omitempty"`
-	Links           Links    `json:"_links,omitempty"`
+	ID              int64         `json:"id,omitempty"`
+	Name            string        `json:"name,omitempty"`
+	Status          WebhookStatus `json:"status,omitempty"`
+	Topic           WebhookTopic  `json:"topic,omitempty"`
+	Resource        string        `json:"resource,omitempty"`
+	Event           string        `json:"event,omitempty"`
+	Hooks           []string      `json:"hooks,omitempty"`
+	DeliveryUrl     string        `json:"delivery_url,omitempty"`
+	Secret          string        `json:"secret,omitempty"`
+	DateCreated     string        `json:"date_created,omitempty"`
+	DateCreatedGmt  string        `json:"date_created_gmt,omitempty"`
+	DateModified    string        `json:"date_modified,omitempty"`
+	DateModifiedGmt string        `json:"date_modified_gmt,omitempty"`
+	Links           Links         `json:"_links,omitempty"`
+	// Error is set on the items of a batch response WooCommerce rejected.
+	Error *BatchItemError `json:"error,omitempty"`
 }
 
 // WebhookListOption config webhook's List method request option
@@ -0,0 +1,569 @@
+package woocommerce
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers WooCommerce sets on webhook deliveries
+const (
+	WebhookHeaderSignature  = "X-WC-Webhook-Signature"
+	WebhookHeaderDeliveryID = "X-WC-Webhook-Delivery-ID"
+	WebhookHeaderID         = "X-WC-Webhook-ID"
+	WebhookHeaderTopic      = "X-WC-Webhook-Topic"
+	WebhookHeaderResource   = "X-WC-Webhook-Resource"
+	WebhookHeaderEvent      = "X-WC-Webhook-Event"
+	WebhookHeaderSource     = "X-WC-Webhook-Source"
+)
+
+const (
+	defaultWebhookMaxAttempts = 5
+	maxWebhookPayloadSize     = 10 << 20
+)
+
+// VerifyWebhookSignature reports whether signature is the base64 encoded
+// HMAC-SHA256 of body keyed with the webhook's secret
+func VerifyWebhookSignature(body []byte, secret, signature string) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// WebhookDelivery is a webhook request received by a WebhookInbox, along with
+// its processing state
+type WebhookDelivery struct {
+	ID        string          `json:"id"`
+	WebhookID int64           `json:"webhook_id,omitempty"`
+	Topic     WebhookTopic    `json:"topic,omitempty"`
+	Resource  string          `json:"resource,omitempty"`
+	Event     string          `json:"event,omitempty"`
+	Source    string          `json:"source,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	// ResourceID and DateModifiedGmt are read from the payload and order the
+	// deliveries of a resource.
+	ResourceID      int64     `json:"resource_id,omitempty"`
+	DateModifiedGmt string    `json:"date_modified_gmt,omitempty"`
+	ReceivedAt      time.Time `json:"received_at"`
+
+	Attempts    int       `json:"attempts,omitempty"`
+	NextAttempt time.Time `json:"next_attempt,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	// Done is set once the delivery was handled, skipped as superseded, or gave up on.
+	Done bool `json:"done,omitempty"`
+	// Failed is set when the handler failed MaxAttempts times.
+	Failed bool `json:"failed,omitempty"`
+	// Superseded is set when a newer update of the resource was handled first.
+	Superseded bool `json:"superseded,omitempty"`
+}
+
+// ResourceKey identifies the object the delivery is about, e.g. "order:123"
+func (d WebhookDelivery) ResourceKey() string {
+	return fmt.Sprintf("%s:%d", d.Resource, d.ResourceID)
+}
+
+// WebhookInboxStore persists the deliveries of a WebhookInbox
+type WebhookInboxStore interface {
+	// Add stores d unless a delivery with the same ID is already stored, and reports whether it did.
+	Add(d WebhookDelivery) (bool, error)
+	// Pending returns the deliveries that are not Done, oldest first.
+	Pending() ([]WebhookDelivery, error)
+	// Update replaces the stored delivery with the same ID.
+	Update(d WebhookDelivery) error
+	// Watermark returns the date_modified_gmt of the latest handled update of a resource.
+	Watermark(resourceKey string) (string, error)
+	SetWatermark(resourceKey, dateModifiedGmt string) error
+}
+
+// WebhookHandlerFunc processes a delivery, a returned error schedules a retry
+type WebhookHandlerFunc func(ctx context.Context, delivery WebhookDelivery) error
+
+// WebhookInbox receives webhook deliveries, stores them durably, drops duplicates
+// by delivery ID and hands them to a handler in date_modified_gmt order per
+// resource, retrying failed deliveries with exponential backoff.
+type WebhookInbox struct {
+	store   WebhookInboxStore
+	handler WebhookHandlerFunc
+	// Secret verifies the signature of the requests served, none is checked when empty.
+	Secret string
+	// MaxAttempts is the number of times a delivery is handled before it is marked Failed.
+	MaxAttempts int
+	// Backoff returns the delay before the given retry, 1s doubling up to an hour by default.
+	Backoff func(attempt int) time.Duration
+
+	now func() time.Time
+	mu  sync.Mutex
+}
+
+// NewWebhookInbox returns an inbox storing deliveries in store and handling them with handler
+func NewWebhookInbox(store WebhookInboxStore, secret string, handler WebhookHandlerFunc) *WebhookInbox {
+	return &WebhookInbox{
+		store:       store,
+		handler:     handler,
+		Secret:      secret,
+		MaxAttempts: defaultWebhookMaxAttempts,
+		Backoff:     defaultWebhookBackoff,
+		now:         time.Now,
+	}
+}
+
+func defaultWebhookBackoff(attempt int) time.Duration {
+	if attempt > 12 {
+		return time.Hour
+	}
+	delay := time.Second << uint(attempt-1)
+	if delay > time.Hour {
+		return time.Hour
+	}
+	return delay
+}
+
+// ServeHTTP stores the delivery and acknowledges it, handling happens in Process.
+// Requests with a bad signature get 401, pings sent when a webhook is created 200.
+func (i *WebhookInbox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadSize))
+	if err != nil {
+		http.Error(w, "cannot read body", http.StatusBadRequest)
+		return
+	}
+	deliveryID := r.Header.Get(WebhookHeaderDeliveryID)
+	if deliveryID == "" {
+		// WooCommerce pings the delivery URL with a form body when a webhook is saved
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if i.Secret != "" && !VerifyWebhookSignature(body, i.Secret, r.Header.Get(WebhookHeaderSignature)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	webhookID, _ := strconv.ParseInt(r.Header.Get(WebhookHeaderID), 10, 64)
+	delivery := WebhookDelivery{
+		ID:        deliveryID,
+		WebhookID: webhookID,
+		Topic:     WebhookTopic(r.Header.Get(WebhookHeaderTopic)),
+		Resource:  r.Header.Get(WebhookHeaderResource),
+		Event:     r.Header.Get(WebhookHeaderEvent),
+		Source:    r.Header.Get(WebhookHeaderSource),
+		Payload:   json.RawMessage(body),
+	}
+	if _, err := i.Receive(delivery); err != nil {
+		// a non-2xx status makes WooCommerce deliver again later
+		http.Error(w, "cannot store delivery", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Receive stores a delivery received by other means than ServeHTTP. It reports
+// false for duplicates, which are dropped.
+func (i *WebhookInbox) Receive(delivery WebhookDelivery) (bool, error) {
+	if delivery.ID == "" {
+		return false, errors.New("webhook delivery has no ID")
+	}
+	var summary struct {
+		ID              int64  `json:"id"`
+		DateModifiedGmt string `json:"date_modified_gmt"`
+	}
+	if len(delivery.Payload) > 0 && json.Unmarshal(delivery.Payload, &summary) == nil {
+		if delivery.ResourceID == 0 {
+			delivery.ResourceID = summary.ID
+		}
+		if delivery.DateModifiedGmt == "" {
+			delivery.DateModifiedGmt = summary.DateModifiedGmt
+		}
+	}
+	if delivery.ReceivedAt.IsZero() {
+		delivery.ReceivedAt = i.now()
+	}
+	return i.store.Add(delivery)
+}
+
+// Process handles the pending deliveries that are due. The deliveries of each
+// resource are handled in date_modified_gmt order, and a resource waits while one
+// of its deliveries is waiting for a retry. Deliveries older than an update already
+// handled for the same resource are marked Superseded without calling the handler.
+// It returns the number of deliveries handled successfully.
+func (i *WebhookInbox) Process(ctx context.Context) (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	pending, err := i.store.Pending()
+	if err != nil {
+		return 0, err
+	}
+	var keys []string
+	byResource := map[string][]WebhookDelivery{}
+	for _, delivery := range pending {
+		key := delivery.ResourceKey()
+		if _, ok := byResource[key]; !ok {
+			keys = append(keys, key)
+		}
+		byResource[key] = append(byResource[key], delivery)
+	}
+
+	handled := 0
+	now := i.now()
+	for _, key := range keys {
+		deliveries := byResource[key]
+		sort.SliceStable(deliveries, func(a, b int) bool {
+			return deliveries[a].DateModifiedGmt < deliveries[b].DateModifiedGmt
+		})
+		watermark, err := i.store.Watermark(key)
+		if err != nil {
+			return handled, err
+		}
+		for _, delivery := range deliveries {
+			if err := ctx.Err(); err != nil {
+				return handled, err
+			}
+			if delivery.DateModifiedGmt != "" && delivery.DateModifiedGmt < watermark {
+				delivery.Done, delivery.Superseded = true, true
+				if err := i.store.Update(delivery); err != nil {
+					return handled, err
+				}
+				continue
+			}
+			if delivery.NextAttempt.After(now) {
+				break
+			}
+
+			delivery.Attempts++
+			if err := i.handler(ctx, delivery); err != nil {
+				delivery.LastError = err.Error()
+				if delivery.Attempts >= i.maxAttempts() {
+					delivery.Done, delivery.Failed = true, true
+				} else {
+					delivery.NextAttempt = now.Add(i.Backoff(delivery.Attempts))
+				}
+				if err := i.store.Update(delivery); err != nil {
+					return handled, err
+				}
+				if !delivery.Done {
+					break
+				}
+				continue
+			}
+
+			delivery.Done, delivery.LastError = true, ""
+			if err := i.store.Update(delivery); err != nil {
+				return handled, err
+			}
+			if delivery.DateModifiedGmt > watermark {
+				watermark = delivery.DateModifiedGmt
+				if err := i.store.SetWatermark(key, watermark); err != nil {
+					return handled, err
+				}
+			}
+			handled++
+		}
+	}
+	return handled, nil
+}
+
+func (i *WebhookInbox) maxAttempts() int {
+	if i.MaxAttempts < 1 {
+		return defaultWebhookMaxAttempts
+	}
+	return i.MaxAttempts
+}
+
+// Run calls Process every interval until ctx is done
+func (i *WebhookInbox) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := i.Process(ctx); err != nil && ctx.Err() == nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// MemoryWebhookInboxStore keeps deliveries in memory, for tests and for
+// receivers that can afford to lose them on restart
+type MemoryWebhookInboxStore struct {
+	mu         sync.Mutex
+	order      []string
+	deliveries map[string]WebhookDelivery
+	watermarks map[string]string
+}
+
+// NewMemoryWebhookInboxStore returns an empty MemoryWebhookInboxStore
+func NewMemoryWebhookInboxStore() *MemoryWebhookInboxStore {
+	return &MemoryWebhookInboxStore{
+		deliveries: map[string]WebhookDelivery{},
+		watermarks: map[string]string{},
+	}
+}
+
+func (s *MemoryWebhookInboxStore) Add(d WebhookDelivery) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.deliveries[d.ID]; exists {
+		return false, nil
+	}
+	s.deliveries[d.ID] = d
+	s.order = append(s.order, d.ID)
+	return true, nil
+}
+
+func (s *MemoryWebhookInboxStore) Pending() ([]WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pending []WebhookDelivery
+	for _, id := range s.order {
+		if d := s.deliveries[id]; !d.Done {
+			pending = append(pending, d)
+		}
+	}
+	return pending, nil
+}
+
+func (s *MemoryWebhookInboxStore) Update(d WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.deliveries[d.ID]; !exists {
+		return fmt.Errorf("webhook delivery %q is not stored", d.ID)
+	}
+	s.deliveries[d.ID] = d
+	return nil
+}
+
+func (s *MemoryWebhookInboxStore) Watermark(resourceKey string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.watermarks[resourceKey], nil
+}
+
+func (s *MemoryWebhookInboxStore) SetWatermark(resourceKey, dateModifiedGmt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watermarks[resourceKey] = dateModifiedGmt
+	return nil
+}
+
+// Get returns the stored delivery with the given ID
+func (s *MemoryWebhookInboxStore) Get(id string) (WebhookDelivery, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deliveries[id]
+	return d, ok
+}
+
+// FileWebhookInboxStore is a MemoryWebhookInboxStore logged to a file of JSON
+// lines: every change is appended and synced, so it survives crashes. The log is
+// rewritten as a snapshot of the store when opened and every thousand changes,
+// dropping the handled deliveries older than Retention, seven days by default,
+// which are kept until then for deduplication. Close releases the file.
+type FileWebhookInboxStore struct {
+	MemoryWebhookInboxStore
+	path      string
+	Retention time.Duration
+
+	fileMu   sync.Mutex
+	file     *os.File
+	records  int
+	snapshot int
+}
+
+// webhookInboxCompactAfter is the number of records appended past the snapshot
+// before the log is compacted
+const webhookInboxCompactAfter = 1000
+
+// webhookInboxRecord is a line of the log, holding a delivery or a watermark
+type webhookInboxRecord struct {
+	Delivery  *WebhookDelivery  `json:"delivery,omitempty"`
+	Watermark *webhookWatermark `json:"watermark,omitempty"`
+}
+
+type webhookWatermark struct {
+	ResourceKey     string `json:"resource_key"`
+	DateModifiedGmt string `json:"date_modified_gmt"`
+}
+
+// OpenFileWebhookInboxStore loads the store logged at path, a missing file is an empty store
+func OpenFileWebhookInboxStore(path string) (*FileWebhookInboxStore, error) {
+	s := &FileWebhookInboxStore{
+		MemoryWebhookInboxStore: MemoryWebhookInboxStore{
+			deliveries: map[string]WebhookDelivery{},
+			watermarks: map[string]string{},
+		},
+		path:      path,
+		Retention: 7 * 24 * time.Hour,
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	for n, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var record webhookInboxRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			if n == len(lines)-1 {
+				// a write torn by a crash, the change was never acknowledged
+				break
+			}
+			return nil, fmt.Errorf("%s:%d: %w", path, n+1, err)
+		}
+		if d := record.Delivery; d != nil {
+			if _, exists := s.deliveries[d.ID]; !exists {
+				s.order = append(s.order, d.ID)
+			}
+			s.deliveries[d.ID] = *d
+		}
+		if w := record.Watermark; w != nil {
+			s.watermarks[w.ResourceKey] = w.DateModifiedGmt
+		}
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileWebhookInboxStore) Add(d WebhookDelivery) (bool, error) {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	added, err := s.MemoryWebhookInboxStore.Add(d)
+	if err != nil || !added {
+		return added, err
+	}
+	return true, s.append(webhookInboxRecord{Delivery: &d})
+}
+
+func (s *FileWebhookInboxStore) Update(d WebhookDelivery) error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	if err := s.MemoryWebhookInboxStore.Update(d); err != nil {
+		return err
+	}
+	return s.append(webhookInboxRecord{Delivery: &d})
+}
+
+func (s *FileWebhookInboxStore) SetWatermark(resourceKey, dateModifiedGmt string) error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	if err := s.MemoryWebhookInboxStore.SetWatermark(resourceKey, dateModifiedGmt); err != nil {
+		return err
+	}
+	return s.append(webhookInboxRecord{Watermark: &webhookWatermark{resourceKey, dateModifiedGmt}})
+}
+
+// Close closes the log, the store must not be changed afterwards
+func (s *FileWebhookInboxStore) Close() error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// append writes record at the end of the log, compacting it when it grew too
+// long. fileMu must be held.
+func (s *FileWebhookInboxStore) append(record webhookInboxRecord) error {
+	if s.file == nil {
+		return fmt.Errorf("%s: webhook inbox store is closed", s.path)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	s.records++
+	if s.records-s.snapshot < webhookInboxCompactAfter {
+		return nil
+	}
+	return s.compact()
+}
+
+// compact drops expired handled deliveries, writes the others and the watermarks
+// to a temporary file renamed over the log, and reopens it for appending
+func (s *FileWebhookInboxStore) compact() error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	s.mu.Lock()
+	cutoff := time.Now().Add(-s.Retention)
+	kept := s.order[:0]
+	for _, id := range s.order {
+		d := s.deliveries[id]
+		if d.Done && s.Retention > 0 && d.ReceivedAt.Before(cutoff) {
+			delete(s.deliveries, id)
+			continue
+		}
+		kept = append(kept, id)
+		encoder.Encode(webhookInboxRecord{Delivery: &d})
+	}
+	s.order = kept
+	records := len(kept)
+	for _, key := range sortedNames(s.watermarks) {
+		encoder.Encode(webhookInboxRecord{Watermark: &webhookWatermark{key, s.watermarks[key]}})
+		records++
+	}
+	s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file, err = os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	s.records, s.snapshot = records, records
+	return nil
+}
@@ -0,0 +1,197 @@
+package woocommerce
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signWebhook(body, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func deliverWebhook(inbox *WebhookInbox, id, body, signature string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	req.Header.Set(WebhookHeaderDeliveryID, id)
+	req.Header.Set(WebhookHeaderTopic, "order.updated")
+	req.Header.Set(WebhookHeaderResource, "order")
+	req.Header.Set(WebhookHeaderEvent, "updated")
+	req.Header.Set(WebhookHeaderSignature, signature)
+	rec := httptest.NewRecorder()
+	inbox.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestWebhookInbox_ServeHTTP(t *testing.T) {
+	store := NewMemoryWebhookInboxStore()
+	inbox := NewWebhookInbox(store, "secret", nil)
+
+	body := `{"id":7,"date_modified_gmt":"2024-01-01T10:00:00"}`
+	if code := deliverWebhook(inbox, "d1", body, signWebhook(body, "secret")); code != http.StatusOK {
+		t.Fatalf("signed delivery got %d", code)
+	}
+	if code := deliverWebhook(inbox, "d1", body, signWebhook(body, "secret")); code != http.StatusOK {
+		t.Fatalf("duplicate delivery got %d", code)
+	}
+	if code := deliverWebhook(inbox, "d2", body, signWebhook(body, "other")); code != http.StatusUnauthorized {
+		t.Fatalf("forged delivery got %d", code)
+	}
+
+	pending, _ := store.Pending()
+	if len(pending) != 1 {
+		t.Fatalf("pending = %+v", pending)
+	}
+	if d := pending[0]; d.ResourceID != 7 || d.DateModifiedGmt != "2024-01-01T10:00:00" || d.Topic != WebhookTopicOrderUpdated {
+		t.Errorf("stored delivery = %+v", d)
+	}
+}
+
+func TestWebhookInbox_Process(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var handled []string
+	failures := map[string]int{"b": 1}
+	store := NewMemoryWebhookInboxStore()
+	inbox := NewWebhookInbox(store, "", func(ctx context.Context, d WebhookDelivery) error {
+		if failures[d.ID] > 0 {
+			failures[d.ID]--
+			return errors.New("temporary failure")
+		}
+		handled = append(handled, d.ID)
+		return nil
+	})
+	inbox.now = func() time.Time { return now }
+
+	// received out of order: b is the older update of order 1
+	for _, d := range []WebhookDelivery{
+		{ID: "c", Resource: "order", Payload: []byte(`{"id":1,"date_modified_gmt":"2024-01-01T11:00:00"}`)},
+		{ID: "b", Resource: "order", Payload: []byte(`{"id":1,"date_modified_gmt":"2024-01-01T10:00:00"}`)},
+		{ID: "x", Resource: "order", Payload: []byte(`{"id":2,"date_modified_gmt":"2024-01-01T09:00:00"}`)},
+	} {
+		if _, err := inbox.Receive(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := inbox.Process(context.Background())
+	if err != nil || n != 1 || strings.Join(handled, ",") != "x" {
+		t.Fatalf("first Process() = %d, %v, handled %v", n, err, handled)
+	}
+	b, _ := store.Get("b")
+	if b.Attempts != 1 || !b.NextAttempt.Equal(now.Add(time.Second)) || b.LastError == "" {
+		t.Errorf("failed delivery = %+v", b)
+	}
+
+	// c waits behind b until its retry is due
+	if n, _ := inbox.Process(context.Background()); n != 0 {
+		t.Errorf("Process() before backoff handled %d", n)
+	}
+	now = now.Add(time.Second)
+	if n, err := inbox.Process(context.Background()); err != nil || n != 2 || strings.Join(handled, ",") != "x,b,c" {
+		t.Fatalf("Process() after backoff = %d, %v, handled %v", n, err, handled)
+	}
+
+	// a late delivery of an update older than c is skipped
+	inbox.Receive(WebhookDelivery{ID: "a", Resource: "order", Payload: []byte(`{"id":1,"date_modified_gmt":"2024-01-01T08:00:00"}`)})
+	inbox.Process(context.Background())
+	if a, _ := store.Get("a"); !a.Superseded || !a.Done || len(handled) != 3 {
+		t.Errorf("late delivery = %+v, handled %v", a, handled)
+	}
+}
+
+func TestWebhookInbox_GivesUp(t *testing.T) {
+	store := NewMemoryWebhookInboxStore()
+	inbox := NewWebhookInbox(store, "", func(ctx context.Context, d WebhookDelivery) error {
+		return errors.New("permanent failure")
+	})
+	inbox.MaxAttempts = 2
+	inbox.Backoff = func(int) time.Duration { return 0 }
+	inbox.Receive(WebhookDelivery{ID: "d", Resource: "product", ResourceID: 3})
+
+	inbox.Process(context.Background())
+	inbox.Process(context.Background())
+	if d, _ := store.Get("d"); !d.Failed || !d.Done || d.Attempts != 2 {
+		t.Errorf("delivery = %+v", d)
+	}
+}
+
+func TestFileWebhookInboxStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inbox.json")
+	store, err := OpenFileWebhookInboxStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(WebhookDelivery{ID: "d1", ReceivedAt: time.Now()})
+	store.Add(WebhookDelivery{ID: "d2", ReceivedAt: time.Now()})
+	store.Update(WebhookDelivery{ID: "d1", ReceivedAt: time.Now(), Done: true})
+	store.SetWatermark("order:1", "2024-01-01T00:00:00")
+	store.Close()
+
+	reopened, err := OpenFileWebhookInboxStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if added, _ := reopened.Add(WebhookDelivery{ID: "d1"}); added {
+		t.Error("handled delivery was not remembered")
+	}
+	if pending, _ := reopened.Pending(); len(pending) != 1 || pending[0].ID != "d2" {
+		t.Errorf("pending = %+v", pending)
+	}
+	if watermark, _ := reopened.Watermark("order:1"); watermark != "2024-01-01T00:00:00" {
+		t.Errorf("watermark = %q", watermark)
+	}
+}
+
+func TestFileWebhookInboxStore_Log(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inbox.jsonl")
+	store, err := OpenFileWebhookInboxStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Retention = time.Hour
+	store.Add(WebhookDelivery{ID: "old", ReceivedAt: time.Now().Add(-2 * time.Hour), Done: true})
+	store.Add(WebhookDelivery{ID: "d1", ReceivedAt: time.Now()})
+	for n := 0; n < webhookInboxCompactAfter; n++ {
+		store.Update(WebhookDelivery{ID: "d1", ReceivedAt: time.Now(), Attempts: n + 1})
+	}
+	store.Close()
+
+	data, _ := os.ReadFile(path)
+	if lines := bytes.Count(data, []byte("\n")); lines > 10 {
+		t.Errorf("log holds %d lines after compaction", lines)
+	}
+	if _, ok := store.Get("old"); ok {
+		t.Error("expired delivery kept after compaction")
+	}
+
+	// a record torn by a crash is ignored
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.WriteString(`{"delivery":{"id":"d2"`)
+	f.Close()
+	reopened, err := OpenFileWebhookInboxStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if d, ok := reopened.Get("d1"); !ok || d.Attempts != webhookInboxCompactAfter {
+		t.Errorf("d1 = %+v, %v", d, ok)
+	}
+	if _, ok := reopened.Get("d2"); ok {
+		t.Error("torn record was loaded")
+	}
+	if added, _ := reopened.Add(WebhookDelivery{ID: "d2"}); !added {
+		t.Error("Add() after a torn record failed")
+	}
+}
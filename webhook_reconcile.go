@@ -0,0 +1,220 @@
+package woocommerce
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// WebhookReconcileOption configures WebhookService.Reconcile
+type WebhookReconcileOption struct {
+	// DryRun computes the report without changing the store.
+	DryRun bool
+	// KeepStale leaves webhooks that match no desired webhook in place.
+	KeepStale bool
+	// RotateSecrets sends the Secret of the desired webhooks that have one to
+	// the existing webhooks too. WooCommerce never returns secrets, so they
+	// are otherwise only set on creation.
+	RotateSecrets bool
+}
+
+// WebhookUpdate is a webhook changed by Reconcile, with the names of its drifted fields
+type WebhookUpdate struct {
+	Webhook Webhook
+	Fields  []string
+}
+
+// WebhookReconcileReport describes what Reconcile changed, or would change on a dry run
+type WebhookReconcileReport struct {
+	Created []Webhook
+	Updated []WebhookUpdate
+	// Reactivated holds the updated webhooks WooCommerce had disabled after failed deliveries.
+	Reactivated []Webhook
+	Deleted     []Webhook
+	Unchanged   []Webhook
+}
+
+// Changed reports whether the store was (or on a dry run would be) modified
+func (r *WebhookReconcileReport) Changed() bool {
+	return len(r.Created)+len(r.Updated)+len(r.Deleted) > 0
+}
+
+// webhookKey identifies a desired webhook in the store: by name when it has one,
+// otherwise by topic and delivery URL.
+func webhookKey(webhook Webhook) string {
+	if webhook.Name != "" {
+		return "name:" + webhook.Name
+	}
+	return fmt.Sprintf("topic:%s|url:%s", webhook.Topic, webhook.DeliveryUrl)
+}
+
+// Reconcile brings the store's webhooks in line with desired. Missing webhooks
+// are created, drifted Topic, Status and DeliveryUrl are updated, webhooks
+// disabled by WooCommerce are re-activated and, unless KeepStale is set, the
+// webhooks matching no desired one are deleted. Desired webhooks without a Status
+// are expected to be active. All changes go through Batch; the items WooCommerce
+// rejects are left out of the report and returned as errors.
+func (w *WebhookServiceOp) Reconcile(desired []Webhook, options WebhookReconcileOption) (*WebhookReconcileReport, error) {
+	current, err := listAllPages(func(o ListOptions) ([]Webhook, error) {
+		return w.List(WebhookListOption{ListOptions: o})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i].ID < current[j].ID })
+
+	byKey := map[string]Webhook{}
+	for _, webhook := range current {
+		for _, key := range []string{webhookKey(webhook), webhookKey(Webhook{Topic: webhook.Topic, DeliveryUrl: webhook.DeliveryUrl})} {
+			if _, taken := byKey[key]; !taken {
+				byKey[key] = webhook
+			}
+		}
+	}
+
+	report := &WebhookReconcileReport{}
+	batch := WebhookBatchOption{}
+	matched := map[int64]bool{}
+	seen := map[string]bool{}
+	for _, want := range desired {
+		key := webhookKey(want)
+		if seen[key] {
+			return nil, fmt.Errorf("webhook %q is declared twice", key)
+		}
+		seen[key] = true
+		if want.Status == "" {
+			want.Status = WebhookStatusActive
+		}
+
+		have, exists := byKey[key]
+		if !exists || matched[have.ID] {
+			want.ID = 0
+			batch.Create = append(batch.Create, want)
+			report.Created = append(report.Created, want)
+			continue
+		}
+		matched[have.ID] = true
+
+		update := Webhook{ID: have.ID}
+		var fields []string
+		if want.Topic != "" && want.Topic != have.Topic {
+			update.Topic = want.Topic
+			fields = append(fields, "topic")
+		}
+		if want.Status != have.Status {
+			update.Status = want.Status
+			fields = append(fields, "status")
+		}
+		if want.DeliveryUrl != "" && want.DeliveryUrl != have.DeliveryUrl {
+			update.DeliveryUrl = want.DeliveryUrl
+			fields = append(fields, "delivery_url")
+		}
+		if options.RotateSecrets && want.Secret != "" {
+			update.Secret = want.Secret
+			fields = append(fields, "secret")
+		}
+		if len(fields) == 0 {
+			report.Unchanged = append(report.Unchanged, have)
+			continue
+		}
+		batch.Update = append(batch.Update, update)
+		report.Updated = append(report.Updated, WebhookUpdate{Webhook: have, Fields: fields})
+		if have.Status == WebhookStatusDisabled && want.Status == WebhookStatusActive {
+			report.Reactivated = append(report.Reactivated, have)
+		}
+	}
+	if !options.KeepStale {
+		for _, webhook := range current {
+			if !matched[webhook.ID] {
+				batch.Delete = append(batch.Delete, webhook.ID)
+				report.Deleted = append(report.Deleted, webhook)
+			}
+		}
+	}
+
+	if options.DryRun {
+		return report, nil
+	}
+	return report, w.batchInChunks(batch, report)
+}
+
+// batchInChunks sends data in batches of at most 100 objects, recording the IDs
+// of the created webhooks in the report and dropping the rejected ones from it
+func (w *WebhookServiceOp) batchInChunks(data WebhookBatchOption, report *WebhookReconcileReport) error {
+	var errs []error
+	created := 0
+	rejected := map[int]bool{}
+	failed := map[int64]bool{}
+	err := batchChunks(data.Create, data.Update, data.Delete, func(create, update []Webhook, ids []int64) error {
+		resource, err := w.Batch(WebhookBatchOption{Create: create, Update: update, Delete: ids})
+		if err != nil {
+			return err
+		}
+		for i, webhook := range resource.Create {
+			if webhook == nil || created+i >= len(report.Created) {
+				continue
+			}
+			if webhook.Error != nil {
+				rejected[created+i] = true
+			} else {
+				report.Created[created+i] = *webhook
+			}
+		}
+		created += len(create)
+		for i, webhook := range resource.Update {
+			if webhook != nil && webhook.Error != nil && i < len(update) {
+				failed[update[i].ID] = true
+			}
+		}
+		for i, webhook := range resource.Delete {
+			if webhook != nil && webhook.Error != nil && i < len(ids) {
+				failed[ids[i]] = true
+			}
+		}
+		errs = append(errs,
+			batchItemsError("webhook", create, resource.Create, webhookLabel, webhookError),
+			batchItemsError("webhook", update, resource.Update, webhookLabel, webhookError),
+			batchDeletesError("webhook", ids, resource.Delete, webhookError))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	createdOK := report.Created[:0]
+	for i, webhook := range report.Created {
+		if !rejected[i] {
+			createdOK = append(createdOK, webhook)
+		}
+	}
+	report.Created = createdOK
+	updatedOK := report.Updated[:0]
+	for _, update := range report.Updated {
+		if !failed[update.Webhook.ID] {
+			updatedOK = append(updatedOK, update)
+		}
+	}
+	report.Updated = updatedOK
+	report.Reactivated = webhooksNotIn(report.Reactivated, failed)
+	report.Deleted = webhooksNotIn(report.Deleted, failed)
+	return errors.Join(errs...)
+}
+
+// webhookLabel names a webhook of a batch in errors, by ID once it has one
+func webhookLabel(webhook Webhook) string {
+	if webhook.ID != 0 {
+		return strconv.FormatInt(webhook.ID, 10)
+	}
+	return webhookKey(webhook)
+}
+
+func webhooksNotIn(webhooks []Webhook, ids map[int64]bool) []Webhook {
+	kept := webhooks[:0]
+	for _, webhook := range webhooks {
+		if !ids[webhook.ID] {
+			kept = append(kept, webhook)
+		}
+	}
+	return kept
+}
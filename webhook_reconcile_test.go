@@ -0,0 +1,142 @@
+package woocommerce
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWebhookServiceOp_Reconcile(t *testing.T) {
+	var batches []WebhookBatchOption
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]Webhook{
+			{ID: 1, Name: "orders", Topic: WebhookTopicOrderUpdated, Status: WebhookStatusDisabled, DeliveryUrl: "https://hooks.example.com/orders"},
+			{ID: 2, Topic: WebhookTopicProductUpdated, Status: WebhookStatusActive, DeliveryUrl: "https://hooks.example.com/products"},
+			{ID: 3, Topic: WebhookTopicCouponCreated, Status: WebhookStatusActive, DeliveryUrl: "https://old.example.com/coupons"},
+		})
+	})
+	mux.HandleFunc("/wp-json/wc/v3/webhooks/batch", func(w http.ResponseWriter, r *http.Request) {
+		var batch WebhookBatchOption
+		json.NewDecoder(r.Body).Decode(&batch)
+		batches = append(batches, batch)
+		resource := WebhookBatchResource{}
+		for i, webhook := range batch.Create {
+			webhook.ID = int64(10 + i)
+			resource.Create = append(resource.Create, &webhook)
+		}
+		json.NewEncoder(w).Encode(resource)
+	})
+	c := newTestClient(t, mux)
+
+	desired := []Webhook{
+		{Name: "orders", Topic: WebhookTopicOrderUpdated, DeliveryUrl: "https://hooks.example.com/v2/orders", Secret: "s1"},
+		{Topic: WebhookTopicProductUpdated, DeliveryUrl: "https://hooks.example.com/products", Secret: "s2"},
+		{Topic: WebhookTopicCustomerCreated, DeliveryUrl: "https://hooks.example.com/customers", Secret: "s3"},
+	}
+
+	report, err := c.Webhook.Reconcile(desired, WebhookReconcileOption{DryRun: true})
+	if err != nil {
+		t.Fatalf("Reconcile() dry run err = %v", err)
+	}
+	if len(batches) != 0 {
+		t.Fatalf("dry run sent %d batches", len(batches))
+	}
+	if len(report.Created) != 1 || len(report.Updated) != 1 || len(report.Reactivated) != 1 || len(report.Deleted) != 1 || len(report.Unchanged) != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	if fields := report.Updated[0].Fields; len(fields) != 2 || fields[0] != "status" || fields[1] != "delivery_url" {
+		t.Errorf("updated fields = %v", fields)
+	}
+
+	report, err = c.Webhook.Reconcile(desired, WebhookReconcileOption{})
+	if err != nil {
+		t.Fatalf("Reconcile() err = %v", err)
+	}
+	if len(batches) != 1 {
+		t.Fatalf("sent %d batches", len(batches))
+	}
+	batch := batches[0]
+	if len(batch.Create) != 1 || batch.Create[0].Status != WebhookStatusActive || batch.Create[0].Topic != WebhookTopicCustomerCreated {
+		t.Errorf("batch create = %+v", batch.Create)
+	}
+	if len(batch.Update) != 1 || batch.Update[0].ID != 1 || batch.Update[0].Status != WebhookStatusActive {
+		t.Errorf("batch update = %+v", batch.Update)
+	}
+	if len(batch.Delete) != 1 || batch.Delete[0] != 3 {
+		t.Errorf("batch delete = %v", batch.Delete)
+	}
+	if report.Created[0].ID != 10 {
+		t.Errorf("created webhook ID = %d", report.Created[0].ID)
+	}
+
+	report, err = c.Webhook.Reconcile(desired, WebhookReconcileOption{DryRun: true, RotateSecrets: true})
+	if err != nil {
+		t.Fatalf("Reconcile() rotating secrets err = %v", err)
+	}
+	if len(report.Updated) != 2 || len(report.Unchanged) != 0 {
+		t.Fatalf("rotating secrets report = %+v", report)
+	}
+	if fields := report.Updated[1].Fields; len(fields) != 1 || fields[0] != "secret" {
+		t.Errorf("rotated fields = %v", fields)
+	}
+
+	if _, err := c.Webhook.Reconcile(append(desired, desired[1]), WebhookReconcileOption{DryRun: true}); err == nil {
+		t.Error("Reconcile() accepted a duplicated webhook")
+	}
+}
+
+func TestWebhookServiceOp_ReconcileRejected(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/wc/v3/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]Webhook{
+			{ID: 1, Name: "orders", Topic: WebhookTopicOrderUpdated, Status: WebhookStatusDisabled, DeliveryUrl: "https://hooks.example.com/orders"},
+			{ID: 3, Topic: WebhookTopicCouponCreated, Status: WebhookStatusActive, DeliveryUrl: "https://old.example.com/coupons"},
+		})
+	})
+	mux.HandleFunc("/wp-json/wc/v3/webhooks/batch", func(w http.ResponseWriter, r *http.Request) {
+		var batch WebhookBatchOption
+		json.NewDecoder(r.Body).Decode(&batch)
+		resource := WebhookBatchResource{}
+		for i, webhook := range batch.Create {
+			if webhook.Topic == WebhookTopicCustomerCreated {
+				resource.Create = append(resource.Create, &Webhook{Error: &BatchItemError{Code: "woocommerce_rest_invalid_delivery_url", Message: "Invalid delivery URL."}})
+				continue
+			}
+			webhook.ID = int64(10 + i)
+			resource.Create = append(resource.Create, &webhook)
+		}
+		for _, webhook := range batch.Update {
+			resource.Update = append(resource.Update, &Webhook{ID: webhook.ID, Error: &BatchItemError{Code: "woocommerce_rest_cannot_edit", Message: "Sorry, you cannot edit this resource."}})
+		}
+		for _, id := range batch.Delete {
+			resource.Delete = append(resource.Delete, &Webhook{ID: id})
+		}
+		json.NewEncoder(w).Encode(resource)
+	})
+	c := newTestClient(t, mux)
+
+	report, err := c.Webhook.Reconcile([]Webhook{
+		{Name: "orders", Topic: WebhookTopicOrderUpdated, DeliveryUrl: "https://hooks.example.com/orders"},
+		{Topic: WebhookTopicCustomerCreated, DeliveryUrl: "ftp://hooks.example.com/customers"},
+		{Topic: WebhookTopicProductUpdated, DeliveryUrl: "https://hooks.example.com/products"},
+	}, WebhookReconcileOption{})
+	if err == nil {
+		t.Fatal("Reconcile() err = nil, want the rejected items")
+	}
+	for _, want := range []string{"woocommerce_rest_invalid_delivery_url", "woocommerce_rest_cannot_edit"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want it to mention %s", err, want)
+		}
+	}
+	if len(report.Created) != 1 || report.Created[0].ID != 11 || report.Created[0].Topic != WebhookTopicProductUpdated {
+		t.Errorf("created = %+v", report.Created)
+	}
+	if len(report.Updated) != 0 || len(report.Reactivated) != 0 {
+		t.Errorf("updated = %+v, reactivated = %+v", report.Updated, report.Reactivated)
+	}
+	if len(report.Deleted) != 1 || report.Deleted[0].ID != 3 {
+		t.Errorf("deleted = %+v", report.Deleted)
+	}
+}
@@ -113,3 +113,18 @@ func TestWebhookServiceOp_Batch(t *testing.T) {
 		t.Logf(" webhook id: %v, webhook status : %v", webhook.ID, webhook.Status)
 	}
 }
+
+func TestWebhookTopic_Valid(t *testing.T) {
+	tests := map[WebhookTopic]bool{
+		WebhookTopicOrderCreated:         true,
+		"action.woocommerce_add_to_cart": true,
+		"action.":                        false,
+		"order.shipped":                  false,
+		"":                               false,
+	}
+	for topic, want := range tests {
+		if got := topic.Valid(); got != want {
+			t.Errorf("WebhookTopic(%q).Valid() = %v, want %v", topic, got, want)
+		}
+	}
+}
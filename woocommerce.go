@@ -7,10 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -21,12 +23,13 @@ import (
 const (
 	UserAgent            = "woocommerce/1.0.0"
 	defaultHttpTimeout   = 10
-	defaultApiPathPrefix = "/wp-json/wc/v3"
+	apiRootPath          = "/wp-json"
+	defaultApiPathPrefix = apiRootPath + "/wc/" + defaultVersion
 	defaultVersion       = "v3"
 )
 
 var (
-	apiVersionRegex = regexp.MustCompile(`^v[0-9]{2}$`)
+	apiVersionRegex = regexp.MustCompile(`^v[1-3]$`)
 )
 
 type App struct {
@@ -56,8 +59,15 @@ type Client struct {
 	token      string
 
 	// max number of retries, defaults to 0 for no retries see WithRetry option
-	retries  int
-	attempts int
+	retries int
+	// middlewares wrap every attempt of every request, see WithMiddleware option
+	middlewares []Middleware
+	// requestMiddlewares wrap every request with its retries, see WithRequestMiddleware option
+	requestMiddlewares []RequestMiddleware
+	// redactor masks secrets and personal data in logs, see WithRedactedFields option
+	redactor *Redactor
+	// unsupportedVersion is a version given to WithVersion and ignored, warned about once the logger is set
+	unsupportedVersion string
 
 	RateLimits           RateLimitInfo
 	Product              ProductService
@@ -101,6 +111,7 @@ func NewClient(app App, shopName string, opts ...Option) *Client {
 		baseURL:    baseURL,
 		version:    defaultVersion,
 		pathPrefix: defaultApiPathPrefix,
+		redactor:   NewRedactor(DefaultRedactedFields...),
 	}
 	c.Product = &ProductServiceOp{client: c}
 	c.Order = &OrderServiceOp{client: c}
@@ -119,6 +130,9 @@ func NewClient(app App, shopName string, opts ...Option) *Client {
 	for _, opt := range opts {
 		opt(c)
 	}
+	if c.unsupportedVersion != "" {
+		c.log.Warnf("woocommerce: unsupported API version %q ignored, using %s; use WithNamespace for other namespaces", c.unsupportedVersion, c.version)
+	}
 
 	return c
 }
@@ -142,15 +156,40 @@ func (c *Client) Do(req *http.Request, v interface{}) error {
 
 // doGetHeaders executes a request, decoding the response into `v` and also returns any response headers.
 func (c *Client) doGetHeaders(req *http.Request, v interface{}) (http.Header, error) {
+	call, err := c.newCall(req)
+	if err != nil {
+		return nil, err
+	}
+	var header http.Header
+	handler := func(call *Call) error {
+		var err error
+		header, err = c.doAttempts(call, v)
+		return err
+	}
+	for i := len(c.requestMiddlewares) - 1; i >= 0; i-- {
+		handler = c.requestMiddlewares[i](handler)
+	}
+	if err := handler(call); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// doAttempts sends call.Request until it succeeds or runs out of retries, counting
+// the attempts and the rate limit waits in call, and decodes the response into `v`
+func (c *Client) doAttempts(call *Call, v interface{}) (http.Header, error) {
 	var resp *http.Response
 	var err error
+	req := call.Request
 	retries := c.retries
-	c.attempts = 0
+	var rateLimitWait time.Duration
 	c.logRequest(req)
 
 	for {
-		c.attempts++
-		resp, err = c.Client.Do(req)
+		call.Attempt++
+		resp, err = c.send(req, call.Attempt, rateLimitWait)
+		call.RateLimitWait += rateLimitWait
+		rateLimitWait = 0
 
 		c.logResponse(resp)
 		if err != nil {
@@ -173,6 +212,7 @@ func (c *Client) doGetHeaders(req *http.Request, v interface{}) (http.Header, er
 			wait := time.Duration(rateLimitErr.RetryAfter) * time.Second
 			c.log.Debugf("rate limited waiting %s", wait.String())
 			time.Sleep(wait)
+			rateLimitWait = wait
 			retries--
 			continue
 		}
@@ -318,8 +358,20 @@ func (c *Client) logRequest(req *http.Request) {
 	if req == nil {
 		return
 	}
+	if l, ok := c.log.(*SlogLogger); ok {
+		if l.debugEnabled(req.Context()) {
+			l.Logger.LogAttrs(req.Context(), slog.LevelDebug, "woocommerce request",
+				slog.String("method", req.Method),
+				slog.String("url", c.redactor.URL(req.URL)),
+				c.bodyAttr(&req.Body))
+		}
+		return
+	}
+	if !c.debugEnabled() {
+		return
+	}
 	if req.URL != nil {
-		c.log.Debugf("%s: %s", req.Method, req.URL.String())
+		c.log.Debugf("%s: %s", req.Method, c.redactor.URL(req.URL))
 	}
 	c.logBody(&req.Body, "SENT: %s")
 }
@@ -328,19 +380,70 @@ func (c *Client) logResponse(res *http.Response) {
 	if res == nil {
 		return
 	}
+	if l, ok := c.log.(*SlogLogger); ok {
+		ctx := context.Background()
+		if res.Request != nil {
+			ctx = res.Request.Context()
+		}
+		if l.debugEnabled(ctx) {
+			l.Logger.LogAttrs(ctx, slog.LevelDebug, "woocommerce response",
+				slog.Int("status", res.StatusCode),
+				c.bodyAttr(&res.Body))
+		}
+		return
+	}
+	if !c.debugEnabled() {
+		return
+	}
 	c.log.Debugf("RECV %d: %s", res.StatusCode, res.Status)
 	c.logBody(&res.Body, "RESP: %s")
 }
 
-func (c *Client) logBody(body *io.ReadCloser, format string) {
-	if body == nil {
-		return
+// debugEnabled reports whether the logger may emit debug messages, see DebugEnabler
+func (c *Client) debugEnabled() bool {
+	if l, ok := c.log.(DebugEnabler); ok {
+		return l.DebugEnabled()
 	}
-	b, _ := io.ReadAll(*body)
-	if len(b) > 0 {
+	return true
+}
+
+func (c *Client) logBody(body *io.ReadCloser, format string) {
+	if b := c.readBody(body); len(b) > 0 {
 		c.log.Debugf(format, string(b))
 	}
+}
+
+// bodyAttr returns the redacted body as a "body" group of its top-level fields,
+// or as a string when it is not a JSON object
+func (c *Client) bodyAttr(body *io.ReadCloser) slog.Attr {
+	b := c.readBody(body)
+	var fields map[string]interface{}
+	if json.Unmarshal(b, &fields) != nil || fields == nil {
+		return slog.String("body", string(b))
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := make([]any, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	return slog.Group("body", attrs...)
+}
+
+// readBody reads body, puts an unread copy back and returns it redacted
+func (c *Client) readBody(body *io.ReadCloser) []byte {
+	if body == nil || *body == nil {
+		return nil
+	}
+	b, _ := io.ReadAll(*body)
 	*body = io.NopCloser(bytes.NewBuffer(b))
+	if len(b) == 0 {
+		return nil
+	}
+	return c.redactor.Body(b)
 }
 
 // ResponseError is A general response error that follows a similar layout to WooCommerce's response
@@ -399,12 +502,7 @@ func (c *Client) CreateAndDo(method, relPath string, data, options, resource int
 
 // createAndDoGetHeaders creates an executes a request while returning the response headers.
 func (c *Client) createAndDoGetHeaders(method, relPath string, data, options, resource interface{}) (http.Header, error) {
-	if strings.HasPrefix(relPath, "/") {
-		relPath = strings.TrimLeft(relPath, "/")
-	}
-
-	relPath = path.Join(c.pathPrefix, relPath)
-	//println("relPath:", relPath)
+	relPath = c.apiPath(relPath)
 	req, err := c.NewRequest(method, relPath, data, options)
 	if err != nil {
 		return nil, err
@@ -412,6 +510,15 @@ func (c *Client) createAndDoGetHeaders(method, relPath string, data, options, re
 	return c.doGetHeaders(req, resource)
 }
 
+// apiPath joins relPath to the API prefix of the client. Paths starting with
+// "/wp-json/" are already complete, they reach other REST namespaces.
+func (c *Client) apiPath(relPath string) string {
+	if strings.HasPrefix(relPath, apiRootPath+"/") {
+		return relPath
+	}
+	return path.Join(c.pathPrefix, strings.TrimLeft(relPath, "/"))
+}
+
 // Creates an API request. A relative URL can be provided in urlStr, which will
 // be resolved to the BaseURL of the Client. Relative URLS should always be
 // specified without a preceding slash. If specified, the value pointed to by
@@ -498,11 +605,7 @@ func (c *Client) CreateAndDoWithContext(ctx context.Context, method, relPath str
 
 // createAndDoGetHeadersWithContext creates and executes a request with context.
 func (c *Client) createAndDoGetHeadersWithContext(ctx context.Context, method, relPath string, data, options, resource interface{}) (http.Header, error) {
-	if strings.HasPrefix(relPath, "/") {
-		relPath = strings.TrimLeft(relPath, "/")
-	}
-
-	relPath = path.Join(c.pathPrefix, relPath)
+	relPath = c.apiPath(relPath)
 	req, err := c.NewRequestWithContext(ctx, method, relPath, data, options)
 	if err != nil {
 		return nil, err
@@ -587,6 +690,10 @@ type ListOptions struct {
 	Offset  int     `url:"offset,omitempty"`
 	Order   string  `url:"order,omitempty"`
 	Orderby string  `url:"orderby,omitempty"`
+	// Fields limits the response to these fields, nested ones as "billing.email".
+	Fields []string `url:"_fields,omitempty,comma"`
+	// Embed includes the linked resources in the response, see Embedded.
+	Embed bool `url:"_embed,omitempty"`
 }
 
 // DeleteOption is the only option for delete order record. dangerous
@@ -0,0 +1,21 @@
+package woocommerce
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestClient returns a client talking to a local TLS server serving handler,
+// so that behaviour built on top of the services can be exercised offline.
+func newTestClient(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	c := NewClient(App{CustomerKey: customerKey, CustomerSecret: customerSecret},
+		strings.TrimPrefix(server.URL, "https://"), opts...)
+	c.Client = server.Client()
+	return c
+}
@@ -0,0 +1,19 @@
+// Package yamlwoo loads woocommerce documents written in YAML, so that the core
+// package does not depend on a YAML parser.
+package yamlwoo
+
+import (
+	"io"
+
+	"github.com/chenyangguang/woocommerce"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadCatalog decodes a YAML catalog, using the field names of the REST API
+func LoadCatalog(r io.Reader) (*woocommerce.Catalog, error) {
+	var document interface{}
+	if err := yaml.NewDecoder(r).Decode(&document); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return woocommerce.CatalogFromDocument(document)
+}
@@ -0,0 +1,33 @@
+package yamlwoo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	catalog, err := LoadCatalog(strings.NewReader(`
+categories:
+  - {name: Apparel, slug: apparel}
+  - {name: Men, slug: men, parent_slug: apparel}
+products:
+  - sku: TSHIRT
+    regular_price: "12.00"
+    stock_quantity: 5
+    categories: [{slug: men}]
+`))
+	if err != nil {
+		t.Fatalf("LoadCatalog() err = %v", err)
+	}
+	if len(catalog.Categories) != 2 || catalog.Categories[1].ParentSlug != "apparel" || catalog.Tags != nil {
+		t.Errorf("categories = %+v, tags = %v", catalog.Categories, catalog.Tags)
+	}
+	product := catalog.Products[0]
+	if product.SKU != "TSHIRT" || product.RegularPrice != "12.00" || *product.StockQuantity != 5 || product.Categories[0].Slug != "men" {
+		t.Errorf("product = %+v", product)
+	}
+
+	if catalog, err := LoadCatalog(strings.NewReader("")); err != nil || catalog.Products != nil {
+		t.Errorf("LoadCatalog() of an empty document = %+v, %v", catalog, err)
+	}
+}